@@ -1,61 +1,221 @@
 package app
 
 import (
+	"log"
 	"os"
+	"slices"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rycln/filer/internal/domain"
 	"github.com/rycln/filer/internal/infrastructure/config"
 	"github.com/rycln/filer/internal/infrastructure/filesystem"
 	"github.com/rycln/filer/internal/infrastructure/filter"
+	"github.com/rycln/filer/internal/infrastructure/lock"
+	"github.com/rycln/filer/internal/infrastructure/report"
 	"github.com/rycln/filer/internal/infrastructure/tui"
+	"github.com/rycln/filer/internal/infrastructure/watcher"
 	"github.com/rycln/filer/internal/usecases"
 )
 
+// watchSettle is how long a new file must stay unchanged in watch mode
+// before it is added to the batch.
+const watchSettle = time.Second
+
 type App struct {
-	tui *tea.Program
+	tui    *tea.Program
+	report string
+	inbox  *watcher.Watcher
+	files  *filesystem.Local
+	lock   *lock.Lock
 }
 
-func New() (*App, error) {
-	cfg, err := config.NewConfigBuilder().WithFlagParsing().Build()
+func New() (app *App, err error) {
+	cfg, err := config.NewConfigBuilder().WithFlagParsing().WithConfigFile().Build()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceLock, err := lock.Acquire(cfg.Source, cfg.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			sourceLock.Release()
+		}
+	}()
+
+	var fsOpts []filesystem.Option
+	if cfg.TargetTmpl != "" {
+		fsOpts = append(fsOpts, filesystem.WithTargetTemplate(cfg.TargetTmpl))
+	}
+	if cfg.Journal != "" {
+		fsOpts = append(fsOpts, filesystem.WithJournal(cfg.Journal))
+	}
+	if cfg.Verify {
+		fsOpts = append(fsOpts, filesystem.WithVerify())
+	}
+	if cfg.Quota != "" {
+		quota, err := domain.ParseSize(cfg.Quota)
+		if err != nil {
+			return nil, err
+		}
+		fsOpts = append(fsOpts, filesystem.WithQuota(quota))
+	}
+
+	filesys, err := filesystem.NewLocal(cfg.Source, cfg.Target, fsOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	filesys, err := filesystem.NewLocal(cfg.Source, cfg.Target)
+	repaired, err := filesys.Repair()
 	if err != nil {
 		return nil, err
 	}
+	for _, msg := range repaired {
+		log.Printf("interrupted copy: %s", msg)
+	}
 
 	filenames, err := filesys.GetFilenames()
 	if err != nil {
 		return nil, err
 	}
 
+	rules := make([]domain.GroupRule, 0, len(cfg.Groups))
+	for _, g := range cfg.Groups {
+		rule, err := domain.ParseGroupRule(g)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	groups := domain.GroupFiles(filenames, rules)
+
 	fileFilter := filter.NewRegexpFilter(cfg.Pattern)
-	filtered, err := fileFilter.Filter(filenames)
+	metaFilter, err := filter.NewMetaFilter(cfg.Exif, filesys.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	filterFiles := func(names []string) ([]string, error) {
+		filtered, err := fileFilter.Filter(names)
+		if err != nil {
+			return nil, err
+		}
+		return metaFilter.Filter(filtered)
+	}
+
+	filtered, err := filterFiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch *domain.FileBatch
+	if cfg.Watch {
+		batch = domain.NewOpenBatch(domain.SelectGroups(groups, filtered))
+	} else {
+		batch, err = domain.NewGroupedBatch(domain.SelectGroups(groups, filtered))
+		if err != nil {
+			return nil, err
+		}
+	}
+	fileProcessor := usecases.NewFileProcessor(filesys, usecases.WithGroups(batch.Members))
+
+	keys, err := tui.NewKeyMap(cfg.Keys)
 	if err != nil {
 		return nil, err
 	}
 
-	batch, err := domain.NewFileBatch(filtered)
+	themeName := cfg.Theme
+	if os.Getenv("NO_COLOR") != "" {
+		themeName = "monochrome"
+	}
+	theme, err := tui.NewTheme(themeName, cfg.Themes)
 	if err != nil {
 		return nil, err
 	}
-	fileProcessor := usecases.NewFileProcessor(filesys)
 
-	p := tea.NewProgram(tui.InitialModel(batch, fileProcessor))
+	opts := []tui.Option{
+		tui.WithKeyMap(keys),
+		tui.WithTheme(theme),
+		tui.WithKeepMode(cfg.KeepMode),
+	}
+	if cfg.Plain || os.Getenv("TERM") == "dumb" {
+		opts = append(opts, tui.WithPlain())
+	}
+	if cfg.List {
+		opts = append(opts, tui.WithList())
+	}
+	if cfg.Target != "" || cfg.TargetTmpl != "" {
+		opts = append(opts, tui.WithTarget())
+	}
+
+	var inbox *watcher.Watcher
+	if cfg.Watch {
+		inbox, err = watcher.New(cfg.Source, watchSettle)
+		if err != nil {
+			return nil, err
+		}
+		arrived := func(names []string) ([]string, error) {
+			names = slices.DeleteFunc(names, filesys.Created)
+			return filterFiles(names)
+		}
+		opts = append(opts, tui.WithWatch(newGroups(inbox.Files(), arrived, rules)))
+	}
+
+	p := tea.NewProgram(tui.InitialModel(batch, fileProcessor, opts...))
 
 	return &App{
-		tui: p,
+		tui:    p,
+		report: cfg.Report,
+		inbox:  inbox,
+		files:  filesys,
+		lock:   sourceLock,
 	}, nil
 }
 
+// newGroups turns files reported by the watcher into batch items.
+// Drops files that accept rejects.
+func newGroups(files <-chan []string, accept func([]string) ([]string, error), rules []domain.GroupRule) <-chan [][]string {
+	groups := make(chan [][]string)
+
+	go func() {
+		defer close(groups)
+		for names := range files {
+			filtered, err := accept(names)
+			if err != nil || len(filtered) == 0 {
+				continue
+			}
+			groups <- domain.SelectGroups(domain.GroupFiles(names, rules), filtered)
+		}
+	}()
+
+	return groups
+}
+
 func (app *App) Run() error {
-	_, err := app.tui.Run()
+	defer app.lock.Release()
+	if app.inbox != nil {
+		defer app.inbox.Close()
+	}
+
+	final, err := app.tui.Run()
 	if err != nil {
 		os.Exit(1)
 	}
 
-	return nil
+	for _, warning := range app.files.Warnings() {
+		log.Printf("warning: %s", warning)
+	}
+
+	if app.report == "" {
+		return nil
+	}
+
+	m, ok := final.(tui.Model)
+	if !ok {
+		return nil
+	}
+
+	return report.WriteJSON(app.report, m.Stats())
 }
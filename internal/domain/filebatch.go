@@ -1,12 +1,61 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
 
-// FileBatch manages sequential file processing with progress tracking.
-// Tracks current position and completion state for batch operations.
+// FileStatus is the decision recorded for a file in a batch.
+type FileStatus int
+
+const (
+	StatusPending FileStatus = iota // Not decided yet
+	StatusKept                      // Kept or moved to target
+	StatusDeleted                   // Deleted from source
+	StatusSkipped                   // Left in place
+	StatusFailed                    // Given up on after an error
+	StatusQueued                    // Operation waiting or running in the background
+)
+
+// String returns a lowercase label for the status.
+func (s FileStatus) String() string {
+	switch s {
+	case StatusKept:
+		return "kept"
+	case StatusDeleted:
+		return "deleted"
+	case StatusSkipped:
+		return "skipped"
+	case StatusFailed:
+		return "failed"
+	case StatusQueued:
+		return "queued"
+	default:
+		return "pending"
+	}
+}
+
+// Decidable reports whether a file with this status can still be acted on.
+// Kept and deleted files are gone from the source, queued ones are
+// about to be.
+func (s FileStatus) Decidable() bool {
+	return s == StatusPending || s == StatusSkipped || s == StatusFailed
+}
+
+// FileBatch manages file processing with progress tracking.
+// Keeps a per-file status and a cursor that moves over a filtered view.
+// An item may stand for a group of files named after its first member.
 type FileBatch struct {
 	filenames []string
-	idx       int
+	mu        sync.RWMutex        // guards members, read by operations in the background
+	members   map[string][]string // members of grouped items
+	statuses  []FileStatus
+	marked    []bool
+	view      []int // indexes of visible files, all unless filtered
+	match     func(string) bool
+	pos       int // cursor position in view
+	done      bool
 }
 
 // NewFileBatch creates a file batch for sequential processing.
@@ -16,41 +65,358 @@ func NewFileBatch(files []string) (*FileBatch, error) {
 		return nil, fmt.Errorf("no files to process")
 	}
 
-	return &FileBatch{
+	b := &FileBatch{
 		filenames: files,
-		idx:       0,
-	}, nil
+		statuses:  make([]FileStatus, len(files)),
+		marked:    make([]bool, len(files)),
+	}
+	b.Filter(nil)
+
+	return b, nil
+}
+
+// NewGroupedBatch creates a batch with one item per group of files.
+// Items are named after the first file of their group.
+// Returns error if groups slice is empty.
+func NewGroupedBatch(groups [][]string) (*FileBatch, error) {
+	b := NewOpenBatch(groups)
+	if len(b.filenames) == 0 {
+		return nil, fmt.Errorf("no files to process")
+	}
+
+	return b, nil
+}
+
+// NewOpenBatch creates a grouped batch that files are appended to later.
+// May start empty, in which case it is complete until files arrive.
+func NewOpenBatch(groups [][]string) *FileBatch {
+	b := &FileBatch{}
+	b.Filter(nil)
+	b.Append(groups)
+	b.done = len(b.filenames) == 0
+
+	return b
 }
 
-// CurrentFile returns the current filename being processed.
-// Returns empty string when batch is complete.
+// Members returns all files of the item named filename.
+// A file that is not grouped is its only member.
+// Safe to call while files are appended.
+func (b *FileBatch) Members(filename string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if group, ok := b.members[filename]; ok {
+		return group
+	}
+	return []string{filename}
+}
+
+// CurrentFile returns the filename under the cursor.
+// Returns empty string when batch is complete or the view is empty.
 func (b *FileBatch) CurrentFile() string {
-	if b.idx >= len(b.filenames) {
+	i := b.current()
+	if i < 0 {
 		return ""
 	}
-	return b.filenames[b.idx]
+	return b.filenames[i]
 }
 
-// NextFile advances to the next file in the batch.
-// Increments internal index counter.
+// NextFile moves the cursor to the next visible pending file, wrapping
+// around. A current file left pending is marked skipped. Completes the
+// batch when no pending file remains; stays put when pending files
+// remain only outside the view.
 func (b *FileBatch) NextFile() {
-	b.idx++
+	cur := b.current()
+	if cur < 0 {
+		return
+	}
+	if b.statuses[cur] == StatusPending {
+		b.statuses[cur] = StatusSkipped
+	}
+
+	if p := b.nextPending(); p >= 0 {
+		b.pos = p
+		return
+	}
+
+	if !slices.Contains(b.statuses, StatusPending) {
+		b.done = true
+		b.pos = len(b.view)
+	}
+}
+
+// PeekNext returns the file NextFile would move to.
+// Returns "" when no other pending file is in the view.
+func (b *FileBatch) PeekNext() string {
+	if b.current() < 0 {
+		return ""
+	}
+
+	p := b.nextPending()
+	if p < 0 {
+		return ""
+	}
+	return b.filenames[b.view[p]]
+}
+
+// nextPending returns the view position of the first pending file
+// after the cursor, wrapping around. Returns -1 if there is none.
+func (b *FileBatch) nextPending() int {
+	n := len(b.view)
+	for i := 1; i < n; i++ {
+		p := (b.pos + i) % n
+		if b.statuses[b.view[p]] == StatusPending {
+			return p
+		}
+	}
+	return -1
 }
 
 // IsComplete checks if all files have been processed.
-// Returns true when index reaches end of file list.
+// Returns true once NextFile finds no pending file left.
 func (b *FileBatch) IsComplete() bool {
-	return b.idx >= len(b.filenames)
+	return b.done
 }
 
-// Progress returns current processing position.
-// Zero-based index of current file.
+// Progress returns the number of decided files in the view.
 func (b *FileBatch) Progress() int {
-	return b.idx
+	decided := 0
+	for _, i := range b.view {
+		if b.statuses[i] != StatusPending {
+			decided++
+		}
+	}
+	return decided
 }
 
-// TotalFiles returns the total number of files in batch.
-// Constant value representing initial file count.
+// TotalFiles returns the number of files in the view.
+// Equals the number of items unless filtered.
 func (b *FileBatch) TotalFiles() int {
-	return len(b.filenames)
+	return len(b.view)
+}
+
+// Cursor returns the view position of the current file.
+// Equals TotalFiles when batch is complete.
+func (b *FileBatch) Cursor() int {
+	return b.pos
+}
+
+// MoveTo places the cursor on the file at view position i.
+// Out of range positions are clamped to the view bounds.
+func (b *FileBatch) MoveTo(i int) {
+	if b.done {
+		return
+	}
+	b.pos = max(0, min(i, len(b.view)-1))
+}
+
+// File returns the filename at view position i.
+func (b *FileBatch) File(i int) string {
+	return b.filenames[b.view[i]]
+}
+
+// Status returns the status of the file at view position i.
+func (b *FileBatch) Status(i int) FileStatus {
+	return b.statuses[b.view[i]]
+}
+
+// CurrentStatus returns the status of the file under the cursor.
+func (b *FileBatch) CurrentStatus() FileStatus {
+	i := b.current()
+	if i < 0 {
+		return StatusPending
+	}
+	return b.statuses[i]
+}
+
+// SetStatus records a decision for the file under the cursor.
+func (b *FileBatch) SetStatus(s FileStatus) {
+	if i := b.current(); i >= 0 {
+		b.setStatus(i, s)
+	}
+}
+
+// StatusOf returns the status of the named file.
+// Unknown files are reported as pending.
+func (b *FileBatch) StatusOf(name string) FileStatus {
+	if i := b.index(name); i >= 0 {
+		return b.statuses[i]
+	}
+	return StatusPending
+}
+
+// SetStatusOf records a decision for the named file.
+func (b *FileBatch) SetStatusOf(name string, s FileStatus) {
+	if i := b.index(name); i >= 0 {
+		b.setStatus(i, s)
+	}
+}
+
+// ToggleMark flips the mark of the file at view position i.
+// Files that cannot be decided any more are never marked.
+func (b *FileBatch) ToggleMark(i int) {
+	j := b.view[i]
+	b.marked[j] = !b.marked[j] && b.statuses[j].Decidable()
+}
+
+// Mark marks the file at view position i if it can still be decided.
+func (b *FileBatch) Mark(i int) {
+	j := b.view[i]
+	b.marked[j] = b.statuses[j].Decidable()
+}
+
+// IsMarked reports whether the file at view position i is marked.
+func (b *FileBatch) IsMarked(i int) bool {
+	return b.marked[b.view[i]]
+}
+
+// MarkedFiles returns all marked files in batch order,
+// including those hidden by a filter.
+func (b *FileBatch) MarkedFiles() []string {
+	var files []string
+	for i, name := range b.filenames {
+		if b.marked[i] {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// PendingFiles returns all undecided files in batch order,
+// including those hidden by a filter.
+func (b *FileBatch) PendingFiles() []string {
+	var files []string
+	for i, name := range b.filenames {
+		if b.statuses[i] == StatusPending {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// ClearMarks unmarks all files.
+func (b *FileBatch) ClearMarks() {
+	clear(b.marked)
+}
+
+// setStatus stores a status and drops the mark of decided files.
+func (b *FileBatch) setStatus(i int, s FileStatus) {
+	b.statuses[i] = s
+	if !s.Decidable() {
+		b.marked[i] = false
+	}
+}
+
+// Filter limits the view to files accepted by match; nil shows all files.
+// Statuses are kept. The cursor stays on the current file if it is
+// visible and pending, otherwise moves to the first visible pending file.
+func (b *FileBatch) Filter(match func(string) bool) {
+	cur := b.current()
+
+	b.view = b.view[:0]
+	for i, name := range b.filenames {
+		if match == nil || match(name) {
+			b.view = append(b.view, i)
+		}
+	}
+	b.match = match
+
+	if b.done {
+		b.pos = len(b.view)
+		return
+	}
+
+	curPos := slices.Index(b.view, cur)
+	if curPos >= 0 && b.statuses[cur] == StatusPending {
+		b.pos = curPos
+		return
+	}
+	for p, i := range b.view {
+		if b.statuses[i] == StatusPending {
+			b.pos = p
+			return
+		}
+	}
+	b.pos = max(curPos, 0)
+}
+
+// Filtered reports whether the view is limited by a filter.
+func (b *FileBatch) Filtered() bool {
+	return b.match != nil
+}
+
+// Append adds groups of files not in the batch yet, keeping the filter.
+// A file reusing the name of one already kept or deleted is new and
+// added again. Reopens a complete batch when a new file is visible.
+// Returns the number of items added.
+func (b *FileBatch) Append(groups [][]string) int {
+	known := make(map[string]bool, len(b.filenames))
+	for i, name := range b.filenames {
+		if s := b.statuses[i]; !s.Decidable() && s != StatusQueued {
+			continue
+		}
+		for _, member := range b.Members(name) {
+			known[member] = true
+		}
+	}
+
+	added, first := 0, -1
+	for _, group := range groups {
+		if len(group) == 0 || slices.ContainsFunc(group, func(name string) bool { return known[name] }) {
+			continue
+		}
+
+		i := len(b.filenames)
+		b.filenames = append(b.filenames, group[0])
+		b.statuses = append(b.statuses, StatusPending)
+		b.marked = append(b.marked, false)
+		b.mu.Lock()
+		if len(group) > 1 {
+			if b.members == nil {
+				b.members = make(map[string][]string)
+			}
+			b.members[group[0]] = group
+		} else {
+			delete(b.members, group[0])
+		}
+		b.mu.Unlock()
+		for _, name := range group {
+			known[name] = true
+		}
+
+		if b.match == nil || b.match(group[0]) {
+			b.view = append(b.view, i)
+			if first < 0 {
+				first = len(b.view) - 1
+			}
+		}
+		added++
+	}
+
+	if b.done && first >= 0 {
+		b.done = false
+		b.pos = first
+	}
+
+	return added
+}
+
+// index returns the position of the named file, the latest one when the
+// name arrived again after an earlier file of that name was processed.
+func (b *FileBatch) index(name string) int {
+	for i := len(b.filenames) - 1; i >= 0; i-- {
+		if b.filenames[i] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// current returns the batch index of the file under the cursor,
+// or -1 when there is none.
+func (b *FileBatch) current() int {
+	if b.done || b.pos >= len(b.view) {
+		return -1
+	}
+	return b.view[b.pos]
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -58,6 +60,46 @@ func TestNewFileBatch(t *testing.T) {
 	})
 }
 
+func TestNewGroupedBatch(t *testing.T) {
+	t.Run("should name items after the first member", func(t *testing.T) {
+		batch, err := NewGroupedBatch([][]string{{"a.jpg", "a.arw"}, {"b.txt"}})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		if batch.TotalFiles() != 2 || batch.File(0) != "a.jpg" || batch.File(1) != "b.txt" {
+			t.Errorf("Expected items a.jpg and b.txt, got %s and %s", batch.File(0), batch.File(1))
+		}
+		if got := batch.Members("a.jpg"); len(got) != 2 || got[1] != "a.arw" {
+			t.Errorf("Expected members [a.jpg a.arw], got %v", got)
+		}
+		if got := batch.Members("b.txt"); len(got) != 1 || got[0] != "b.txt" {
+			t.Errorf("Expected single member b.txt, got %v", got)
+		}
+	})
+
+	t.Run("should return error for no groups", func(t *testing.T) {
+		if _, err := NewGroupedBatch(nil); err == nil {
+			t.Error("Expected error for empty batch")
+		}
+	})
+}
+
+func TestNewOpenBatch(t *testing.T) {
+	t.Run("should start complete without files", func(t *testing.T) {
+		batch := NewOpenBatch(nil)
+
+		if !batch.IsComplete() || batch.CurrentFile() != "" {
+			t.Error("Expected empty batch to be complete")
+		}
+
+		batch.Append([][]string{{"scan.pdf"}})
+		if batch.IsComplete() || batch.CurrentFile() != "scan.pdf" {
+			t.Errorf("Expected scan.pdf after append, got %q", batch.CurrentFile())
+		}
+	})
+}
+
 func TestFileBatch_CurrentFile(t *testing.T) {
 	t.Run("should return first file initially", func(t *testing.T) {
 		files := []string{"file1.txt", "file2.txt"}
@@ -152,6 +194,35 @@ func TestFileBatch_NextFile(t *testing.T) {
 	})
 }
 
+func TestFileBatch_PeekNext(t *testing.T) {
+	t.Run("should return next pending file without moving", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.txt", "b.txt", "c.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		batch.MoveTo(1)
+		batch.SetStatusOf("c.txt", StatusKept)
+
+		if got := batch.PeekNext(); got != "a.txt" {
+			t.Errorf("Expected a.txt after wrapping, got %q", got)
+		}
+		if batch.CurrentFile() != "b.txt" {
+			t.Error("PeekNext should not move the cursor")
+		}
+	})
+
+	t.Run("should return empty string for the last pending file", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		if got := batch.PeekNext(); got != "" {
+			t.Errorf("Expected no next file, got %q", got)
+		}
+	})
+}
+
 func TestFileBatch_IsComplete(t *testing.T) {
 	t.Run("should not be complete for non-empty batch initially", func(t *testing.T) {
 		files := []string{"file1.txt"}
@@ -234,7 +305,7 @@ func TestFileBatch_Progress(t *testing.T) {
 		}
 	})
 
-	t.Run("should handle progress beyond file count", func(t *testing.T) {
+	t.Run("should cap progress at file count", func(t *testing.T) {
 		files := []string{"file1.txt"}
 		batch, err := NewFileBatch(files)
 		if err != nil {
@@ -245,8 +316,8 @@ func TestFileBatch_Progress(t *testing.T) {
 		batch.NextFile()
 		batch.NextFile()
 
-		if batch.Progress() != 3 {
-			t.Errorf("Expected progress 3, got %d", batch.Progress())
+		if batch.Progress() != 1 {
+			t.Errorf("Expected progress 1, got %d", batch.Progress())
 		}
 	})
 }
@@ -294,3 +365,415 @@ func TestFileBatch_TotalFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestFileBatch_Status(t *testing.T) {
+	t.Run("should start with all files pending", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		for i := 0; i < batch.TotalFiles(); i++ {
+			if batch.Status(i) != StatusPending {
+				t.Errorf("Expected file %d pending, got %v", i, batch.Status(i))
+			}
+		}
+	})
+
+	t.Run("should keep recorded status when moving on", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.SetStatus(StatusDeleted)
+		batch.NextFile()
+
+		if batch.Status(0) != StatusDeleted {
+			t.Errorf("Expected deleted, got %v", batch.Status(0))
+		}
+		if batch.CurrentFile() != "file2.txt" {
+			t.Errorf("Expected 'file2.txt', got '%s'", batch.CurrentFile())
+		}
+	})
+
+	t.Run("should mark undecided file as skipped on next", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.NextFile()
+
+		if batch.Status(0) != StatusSkipped {
+			t.Errorf("Expected skipped, got %v", batch.Status(0))
+		}
+	})
+
+	t.Run("should report decidable statuses", func(t *testing.T) {
+		for _, s := range []FileStatus{StatusPending, StatusSkipped, StatusFailed} {
+			if !s.Decidable() {
+				t.Errorf("Expected %v to be decidable", s)
+			}
+		}
+		for _, s := range []FileStatus{StatusKept, StatusDeleted, StatusQueued} {
+			if s.Decidable() {
+				t.Errorf("Expected %v to be final", s)
+			}
+		}
+	})
+}
+
+func TestFileBatch_MoveTo(t *testing.T) {
+	t.Run("should move cursor to any file", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt", "file3.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.MoveTo(2)
+
+		if batch.Cursor() != 2 || batch.CurrentFile() != "file3.txt" {
+			t.Errorf("Expected cursor on file3.txt, got %d '%s'", batch.Cursor(), batch.CurrentFile())
+		}
+	})
+
+	t.Run("should clamp out of range indexes", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.MoveTo(10)
+		if batch.Cursor() != 1 {
+			t.Errorf("Expected cursor 1, got %d", batch.Cursor())
+		}
+
+		batch.MoveTo(-1)
+		if batch.Cursor() != 0 {
+			t.Errorf("Expected cursor 0, got %d", batch.Cursor())
+		}
+	})
+
+	t.Run("should wrap to earlier pending file after jump", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt", "file3.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.MoveTo(2)
+		batch.SetStatus(StatusKept)
+		batch.NextFile()
+
+		if batch.CurrentFile() != "file1.txt" {
+			t.Errorf("Expected 'file1.txt', got '%s'", batch.CurrentFile())
+		}
+		if batch.IsComplete() {
+			t.Error("Batch should not be complete while files are pending")
+		}
+	})
+
+	t.Run("should complete when no pending file remains", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.MoveTo(1)
+		batch.SetStatus(StatusKept)
+		batch.NextFile()
+		batch.SetStatus(StatusDeleted)
+		batch.NextFile()
+
+		if !batch.IsComplete() {
+			t.Error("Expected batch to be complete")
+		}
+		if batch.Progress() != 2 {
+			t.Errorf("Expected progress 2, got %d", batch.Progress())
+		}
+	})
+}
+
+func TestFileBatch_Filter(t *testing.T) {
+	hasSuffix := func(suffix string) func(string) bool {
+		return func(name string) bool { return strings.HasSuffix(name, suffix) }
+	}
+
+	t.Run("should limit view to matching files", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt", "c.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.Filter(hasSuffix(".jpg"))
+
+		if !batch.Filtered() {
+			t.Error("Expected batch to be filtered")
+		}
+		if batch.TotalFiles() != 2 {
+			t.Errorf("Expected 2 visible files, got %d", batch.TotalFiles())
+		}
+		if batch.File(1) != "c.jpg" {
+			t.Errorf("Expected 'c.jpg' at position 1, got '%s'", batch.File(1))
+		}
+		if batch.CurrentFile() != "a.jpg" {
+			t.Errorf("Expected cursor to stay on 'a.jpg', got '%s'", batch.CurrentFile())
+		}
+	})
+
+	t.Run("should keep decisions across filters", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt", "c.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.SetStatus(StatusKept)
+		batch.Filter(hasSuffix(".jpg"))
+
+		if batch.Progress() != 1 {
+			t.Errorf("Expected progress 1 in filtered view, got %d", batch.Progress())
+		}
+		if batch.CurrentFile() != "c.jpg" {
+			t.Errorf("Expected cursor on first pending match 'c.jpg', got '%s'", batch.CurrentFile())
+		}
+
+		batch.Filter(nil)
+
+		if batch.Filtered() || batch.TotalFiles() != 3 {
+			t.Errorf("Expected all 3 files after clearing filter, got %d", batch.TotalFiles())
+		}
+		if batch.Status(0) != StatusKept {
+			t.Errorf("Expected 'a.jpg' still kept, got %s", batch.Status(0))
+		}
+		if batch.CurrentFile() != "c.jpg" {
+			t.Errorf("Expected cursor to stay on 'c.jpg', got '%s'", batch.CurrentFile())
+		}
+	})
+
+	t.Run("should stay on last visible file while others are pending", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.Filter(hasSuffix(".jpg"))
+		batch.SetStatus(StatusDeleted)
+		batch.NextFile()
+
+		if batch.IsComplete() {
+			t.Error("Batch should not be complete while hidden files are pending")
+		}
+		if batch.CurrentFile() != "a.jpg" {
+			t.Errorf("Expected cursor to stay on 'a.jpg', got '%s'", batch.CurrentFile())
+		}
+	})
+
+	t.Run("should have no current file when nothing matches", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.Filter(hasSuffix(".png"))
+		batch.NextFile()
+
+		if batch.TotalFiles() != 0 || batch.CurrentFile() != "" {
+			t.Errorf("Expected empty view, got %d files and '%s'", batch.TotalFiles(), batch.CurrentFile())
+		}
+		if batch.IsComplete() {
+			t.Error("Empty view should not complete the batch")
+		}
+		batch.Filter(nil)
+		if batch.Status(0) != StatusPending {
+			t.Errorf("Expected hidden file left pending, got %s", batch.Status(0))
+		}
+	})
+}
+
+func TestFileBatch_Append(t *testing.T) {
+	t.Run("should reopen a complete batch with new files", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		batch.SetStatus(StatusKept)
+		batch.NextFile()
+
+		added := batch.Append([][]string{{"b.jpg", "b.arw"}, {"c.txt"}})
+
+		if added != 2 {
+			t.Errorf("Expected 2 items added, got %d", added)
+		}
+		if batch.IsComplete() || batch.CurrentFile() != "b.jpg" {
+			t.Errorf("Expected cursor on b.jpg, got %q", batch.CurrentFile())
+		}
+		if got := batch.Members("b.jpg"); len(got) != 2 {
+			t.Errorf("Expected group members, got %v", got)
+		}
+	})
+
+	t.Run("should skip files already in the batch", func(t *testing.T) {
+		batch, err := NewGroupedBatch([][]string{{"a.jpg", "a.arw"}})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		if added := batch.Append([][]string{{"a.arw"}, {"a.jpg"}}); added != 0 {
+			t.Errorf("Expected no items added, got %d", added)
+		}
+		if batch.TotalFiles() != 1 {
+			t.Errorf("Expected 1 item, got %d", batch.TotalFiles())
+		}
+	})
+
+	t.Run("should add a file reusing the name of a processed one", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"scan.pdf"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		batch.SetStatusOf("scan.pdf", StatusKept)
+		batch.NextFile()
+
+		if added := batch.Append([][]string{{"scan.pdf"}}); added != 1 {
+			t.Fatalf("Expected the new scan.pdf added, got %d", added)
+		}
+
+		if batch.IsComplete() || batch.CurrentFile() != "scan.pdf" {
+			t.Errorf("Expected cursor on the new scan.pdf, got %q", batch.CurrentFile())
+		}
+		if batch.StatusOf("scan.pdf") != StatusPending {
+			t.Errorf("Expected the new scan.pdf pending, got %v", batch.StatusOf("scan.pdf"))
+		}
+		batch.SetStatusOf("scan.pdf", StatusDeleted)
+		if batch.Status(0) != StatusKept || batch.Status(1) != StatusDeleted {
+			t.Errorf("Expected decisions on each scan.pdf, got %v and %v", batch.Status(0), batch.Status(1))
+		}
+	})
+
+	t.Run("should let members be read while files are appended", func(t *testing.T) {
+		batch := NewOpenBatch([][]string{{"a.jpg", "a.arw"}})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range 1000 {
+				if got := batch.Members("a.jpg"); len(got) != 2 {
+					t.Errorf("Expected group members of a.jpg, got %v", got)
+					return
+				}
+			}
+		}()
+		for i := range 1000 {
+			name := fmt.Sprintf("scan%d", i)
+			batch.Append([][]string{{name + ".jpg", name + ".arw"}})
+		}
+		<-done
+	})
+
+	t.Run("should apply the active filter to new files", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		batch.Filter(func(name string) bool { return strings.HasSuffix(name, ".jpg") })
+
+		batch.Append([][]string{{"b.txt"}, {"c.jpg"}})
+
+		if batch.TotalFiles() != 2 || batch.File(1) != "c.jpg" {
+			t.Errorf("Expected only c.jpg to become visible, got %d files", batch.TotalFiles())
+		}
+		batch.Filter(nil)
+		if batch.TotalFiles() != 3 {
+			t.Errorf("Expected 3 files without filter, got %d", batch.TotalFiles())
+		}
+	})
+}
+
+func TestFileBatch_Marks(t *testing.T) {
+	t.Run("should toggle marks of decidable files", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt", "c.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.SetStatus(StatusKept)
+		batch.ToggleMark(0)
+		batch.ToggleMark(1)
+		batch.Mark(2)
+
+		if batch.IsMarked(0) {
+			t.Error("Kept file should not be marked")
+		}
+		if !batch.IsMarked(1) || !batch.IsMarked(2) {
+			t.Error("Pending files should be marked")
+		}
+
+		batch.ToggleMark(1)
+		if batch.IsMarked(1) {
+			t.Error("Second toggle should unmark the file")
+		}
+	})
+
+	t.Run("should list marks hidden by a filter", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt", "c.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.Mark(0)
+		batch.Mark(1)
+		batch.Filter(func(name string) bool { return strings.HasSuffix(name, ".txt") })
+
+		marked := batch.MarkedFiles()
+		if len(marked) != 2 || marked[0] != "a.jpg" || marked[1] != "b.txt" {
+			t.Errorf("Expected [a.jpg b.txt], got %v", marked)
+		}
+
+		batch.ClearMarks()
+		if len(batch.MarkedFiles()) != 0 {
+			t.Error("Expected no marks after clearing")
+		}
+	})
+
+	t.Run("should unmark files decided by name", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.jpg", "b.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.Mark(0)
+		batch.Mark(1)
+		batch.SetStatusOf("a.jpg", StatusDeleted)
+		batch.SetStatusOf("b.txt", StatusFailed)
+
+		if batch.StatusOf("a.jpg") != StatusDeleted {
+			t.Errorf("Expected a.jpg deleted, got %s", batch.StatusOf("a.jpg"))
+		}
+		if batch.IsMarked(0) {
+			t.Error("Deleted file should lose its mark")
+		}
+		if !batch.IsMarked(1) {
+			t.Error("Failed file should stay marked for another try")
+		}
+		if batch.StatusOf("missing") != StatusPending {
+			t.Error("Unknown file should be reported as pending")
+		}
+	})
+}
+
+func TestFileBatch_PendingFiles(t *testing.T) {
+	t.Run("should list undecided files including hidden ones", func(t *testing.T) {
+		batch, err := NewFileBatch([]string{"a.log", "b.log", "c.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+
+		batch.SetStatus(StatusSkipped)
+		batch.Filter(func(name string) bool { return name == "b.log" })
+
+		pending := batch.PendingFiles()
+		if len(pending) != 2 || pending[0] != "b.log" || pending[1] != "c.txt" {
+			t.Errorf("Expected [b.log c.txt], got %v", pending)
+		}
+	})
+}
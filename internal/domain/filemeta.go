@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"io/fs"
+	"time"
+)
+
+// FileMeta describes a file for display next to its name.
+// Fields the platform cannot report are left zero.
+type FileMeta struct {
+	Size       int64
+	Mode       fs.FileMode
+	ModTime    time.Time
+	BirthTime  time.Time
+	Owner      string
+	Group      string
+	Links      uint64
+	Symlink    bool
+	LinkTarget string
+	Parent     string
+	Taken      time.Time // EXIF capture date of images
+	Camera     string
+	Width      int // Image dimensions as displayed, after rotation
+	Height     int
+	Rotated    bool // EXIF orientation turns the image by 90 degrees
+	GPS        bool
+}
+
+// IsImage reports whether image metadata was found for the file.
+func (m FileMeta) IsImage() bool {
+	return m.Width > 0 || m.Camera != "" || !m.Taken.IsZero()
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GroupRule lists extensions of files that belong together when they
+// share a stem, e.g. RAW, JPEG and XMP sidecar. The first extension
+// present names the group. Extensions have no dot.
+type GroupRule []string
+
+// ParseGroupRule parses a comma separated extension list like "jpg,arw,xmp".
+// Extensions are case-insensitive; a leading dot is ignored.
+func ParseGroupRule(s string) (GroupRule, error) {
+	var rule GroupRule
+	for _, ext := range strings.Split(s, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext == "" {
+			return nil, fmt.Errorf("empty extension in group rule %q", s)
+		}
+		rule = append(rule, ext)
+	}
+	if len(rule) < 2 {
+		return nil, fmt.Errorf("group rule %q needs at least two extensions", s)
+	}
+	return rule, nil
+}
+
+// GroupFiles bundles files sharing a stem whose extensions are listed in
+// one rule. Earlier rules win. Other files form groups of their own.
+// Groups appear in the order of their first file; members are ordered
+// as the rule lists their extensions.
+func GroupFiles(filenames []string, rules []GroupRule) [][]string {
+	type key struct {
+		rule int
+		stem string
+	}
+
+	groupOf := make(map[string]key)
+	for r, rule := range rules {
+		candidates := make(map[key][]string)
+		for _, name := range filenames {
+			if _, ok := groupOf[name]; ok || !slices.Contains(rule, extOf(name)) {
+				continue
+			}
+			k := key{r, strings.TrimSuffix(name, filepath.Ext(name))}
+			candidates[k] = append(candidates[k], name)
+		}
+		for k, names := range candidates {
+			if len(names) < 2 {
+				continue
+			}
+			for _, name := range names {
+				groupOf[name] = k
+			}
+		}
+	}
+
+	var groups [][]string
+	index := make(map[key]int)
+	for _, name := range filenames {
+		k, ok := groupOf[name]
+		if !ok {
+			groups = append(groups, []string{name})
+			continue
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], name)
+	}
+
+	for k, i := range index {
+		rule := rules[k.rule]
+		slices.SortStableFunc(groups[i], func(a, b string) int {
+			return slices.Index(rule, extOf(a)) - slices.Index(rule, extOf(b))
+		})
+	}
+
+	return groups
+}
+
+// SelectGroups returns groups with at least one member in filenames.
+// Lets file filters pick groups without splitting them.
+func SelectGroups(groups [][]string, filenames []string) [][]string {
+	selected := make(map[string]bool, len(filenames))
+	for _, name := range filenames {
+		selected[name] = true
+	}
+
+	var kept [][]string
+	for _, group := range groups {
+		if slices.ContainsFunc(group, func(name string) bool { return selected[name] }) {
+			kept = append(kept, group)
+		}
+	}
+	return kept
+}
+
+// extOf returns the lower case extension of name without the dot.
+func extOf(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGroupRule(t *testing.T) {
+	t.Run("should normalize extensions", func(t *testing.T) {
+		rule, err := ParseGroupRule("JPG, .arw,xmp")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := (GroupRule{"jpg", "arw", "xmp"}); !reflect.DeepEqual(rule, want) {
+			t.Errorf("Expected %v, got %v", want, rule)
+		}
+	})
+
+	for _, s := range []string{"jpg", "jpg,,arw", ""} {
+		t.Run("should reject "+s, func(t *testing.T) {
+			if _, err := ParseGroupRule(s); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestGroupFiles(t *testing.T) {
+	rules := []GroupRule{{"jpg", "arw", "xmp"}, {"cr2", "jpg"}}
+
+	tests := []struct {
+		name  string
+		files []string
+		want  [][]string
+	}{
+		{
+			name:  "raw, jpeg and sidecar",
+			files: []string{"DSC001.ARW", "DSC001.JPG", "DSC001.xmp", "DSC002.JPG", "notes.txt"},
+			want:  [][]string{{"DSC001.JPG", "DSC001.ARW", "DSC001.xmp"}, {"DSC002.JPG"}, {"notes.txt"}},
+		},
+		{
+			name:  "later rule for files left alone",
+			files: []string{"IMG_1.CR2", "IMG_1.jpg"},
+			want:  [][]string{{"IMG_1.CR2", "IMG_1.jpg"}},
+		},
+		{
+			name:  "unlisted extension with same stem",
+			files: []string{"DSC001.ARW", "DSC001.mov"},
+			want:  [][]string{{"DSC001.ARW"}, {"DSC001.mov"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should group "+tt.name, func(t *testing.T) {
+			if got := GroupFiles(tt.files, rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("should leave files alone without rules", func(t *testing.T) {
+		got := GroupFiles([]string{"a.jpg", "a.arw"}, nil)
+		if want := [][]string{{"a.jpg"}, {"a.arw"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+}
+
+func TestSelectGroups(t *testing.T) {
+	t.Run("should keep groups with a selected member", func(t *testing.T) {
+		groups := [][]string{{"a.jpg", "a.arw"}, {"b.txt"}, {"c.jpg"}}
+
+		got := SelectGroups(groups, []string{"a.arw", "c.jpg"})
+
+		if want := [][]string{{"a.jpg", "a.arw"}, {"c.jpg"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+// progressKey is the context key of the progress callback.
+type progressKey struct{}
+
+// WithProgress returns a context whose file operations report the bytes
+// copied so far to report.
+func WithProgress(ctx context.Context, report func(copied int64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ProgressFrom returns the progress callback of ctx.
+// Does nothing for contexts without one.
+func ProgressFrom(ctx context.Context) func(copied int64) {
+	if report, ok := ctx.Value(progressKey{}).(func(int64)); ok {
+		return report
+	}
+	return func(int64) {}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	t.Run("should deliver progress to the callback", func(t *testing.T) {
+		var got int64
+		ctx := WithProgress(context.Background(), func(copied int64) { got = copied })
+
+		ProgressFrom(ctx)(42)
+
+		if got != 42 {
+			t.Errorf("Expected 42 bytes reported, got %d", got)
+		}
+	})
+
+	t.Run("should ignore progress without a callback", func(t *testing.T) {
+		ProgressFrom(context.Background())(42)
+	})
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Errors of files that do not fit into the target.
+var (
+	ErrNoSpace = errors.New("not enough space on target")
+	ErrQuota   = errors.New("target quota exceeded")
+)
+
+// TargetSpace is the room left for kept files.
+// Negative values are unknown or unlimited.
+type TargetSpace struct {
+	Free  int64 // Bytes free on the target file system
+	Quota int64 // Bytes the session may still move
+}
+
+// Known reports whether anything is known about the space left.
+func (s TargetSpace) Known() bool {
+	return s.Free >= 0 || s.Quota >= 0
+}
+
+// Fits reports whether size bytes fit into the space left.
+func (s TargetSpace) Fits(size int64) bool {
+	return (s.Free < 0 || size <= s.Free) && (s.Quota < 0 || size <= s.Quota)
+}
+
+// sizeUnits maps size suffixes to their factor. Decimal units such as GB
+// count in powers of 1000, binary units such as GiB in powers of 1024.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1e3,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1e6,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1e9,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1e12,
+	"TIB": 1 << 40,
+}
+
+// ParseSize parses a byte size such as "50GB", "1.5 GiB" or "4096".
+// Units are case-insensitive; single letters are binary units.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit: %q", s)
+	}
+
+	return int64(n * float64(unit)), nil
+}
+
+// FormatSize renders a byte count with binary unit prefixes.
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
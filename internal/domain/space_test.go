@@ -0,0 +1,49 @@
+package domain
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"4096", 4096},
+		{"50GB", 50_000_000_000},
+		{"50gb", 50_000_000_000},
+		{"1.5 GiB", 3 << 29},
+		{"2G", 2 << 30},
+		{"512 B", 512},
+	}
+	for _, tt := range tests {
+		t.Run("should parse "+tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSize(%q) = %d (%v), want %d", tt.in, got, err, tt.want)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "GB", "50 parsecs", "-1GB", "1.2.3MB"} {
+		t.Run("should reject "+in, func(t *testing.T) {
+			if _, err := ParseSize(in); err == nil {
+				t.Errorf("Expected error for %q", in)
+			}
+		})
+	}
+}
+
+func TestTargetSpace_Fits(t *testing.T) {
+	t.Run("should fit within free space and quota", func(t *testing.T) {
+		space := TargetSpace{Free: 100, Quota: 50}
+		if !space.Fits(50) || space.Fits(51) {
+			t.Errorf("Expected 50 bytes to fit and 51 not, got %+v", space)
+		}
+	})
+
+	t.Run("should treat negative values as unlimited", func(t *testing.T) {
+		space := TargetSpace{Free: -1, Quota: -1}
+		if !space.Fits(1<<50) || space.Known() {
+			t.Errorf("Expected unknown space to fit anything, got %+v", space)
+		}
+	})
+}
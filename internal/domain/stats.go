@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NoExtension labels files without an extension in per-extension stats.
+const NoExtension = "(none)"
+
+// DecisionCounts holds the number of files per decision.
+type DecisionCounts struct {
+	Kept    int
+	Deleted int
+	Skipped int
+	Failed  int
+}
+
+// Total returns the number of decisions made.
+func (c DecisionCounts) Total() int {
+	return c.Kept + c.Deleted + c.Skipped + c.Failed
+}
+
+// SessionStats accumulates the outcome of a sorting session.
+// Tracks decisions, byte counts and timing overall and per extension.
+type SessionStats struct {
+	DecisionCounts
+	BytesMoved int64
+	BytesFreed int64
+
+	started  time.Time
+	finished time.Time
+	byExt    map[string]*DecisionCounts
+}
+
+// NewSessionStats creates empty statistics for a session started at start.
+func NewSessionStats(start time.Time) *SessionStats {
+	return &SessionStats{
+		started: start,
+		byExt:   make(map[string]*DecisionCounts),
+	}
+}
+
+// RecordKeep counts a kept file of the given size.
+func (s *SessionStats) RecordKeep(filename string, size int64) {
+	s.Kept++
+	s.BytesMoved += size
+	s.ext(filename).Kept++
+}
+
+// RecordDelete counts a deleted file of the given size.
+func (s *SessionStats) RecordDelete(filename string, size int64) {
+	s.Deleted++
+	s.BytesFreed += size
+	s.ext(filename).Deleted++
+}
+
+// RecordSkip counts a skipped file.
+func (s *SessionStats) RecordSkip(filename string) {
+	s.Skipped++
+	s.ext(filename).Skipped++
+}
+
+// RecordFailure counts a file given up on after an error.
+func (s *SessionStats) RecordFailure(filename string) {
+	s.Failed++
+	s.ext(filename).Failed++
+}
+
+// Withdraw removes an earlier skip or failure of a file
+// that is being decided again.
+func (s *SessionStats) Withdraw(filename string, status FileStatus) {
+	switch status {
+	case StatusSkipped:
+		s.Skipped--
+		s.ext(filename).Skipped--
+	case StatusFailed:
+		s.Failed--
+		s.ext(filename).Failed--
+	}
+}
+
+// Finish stops the session clock.
+// Later calls are ignored so the first completion time wins.
+func (s *SessionStats) Finish(end time.Time) {
+	if s.finished.IsZero() {
+		s.finished = end
+	}
+}
+
+// Elapsed returns the session duration.
+// Measured up to now while the session is still running.
+func (s *SessionStats) Elapsed() time.Duration {
+	if s.finished.IsZero() {
+		return time.Since(s.started)
+	}
+	return s.finished.Sub(s.started)
+}
+
+// AverageDecision returns the mean time spent per decision.
+// Zero when no decision has been made yet.
+func (s *SessionStats) AverageDecision() time.Duration {
+	total := s.Total()
+	if total == 0 {
+		return 0
+	}
+	return s.Elapsed() / time.Duration(total)
+}
+
+// Extensions returns the seen extensions in sorted order.
+func (s *SessionStats) Extensions() []string {
+	exts := make([]string, 0, len(s.byExt))
+	for ext := range s.byExt {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+	return exts
+}
+
+// ByExtension returns decision counts for a single extension.
+func (s *SessionStats) ByExtension(ext string) DecisionCounts {
+	if c, ok := s.byExt[ext]; ok {
+		return *c
+	}
+	return DecisionCounts{}
+}
+
+func (s *SessionStats) ext(filename string) *DecisionCounts {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		ext = NoExtension
+	}
+
+	c, ok := s.byExt[ext]
+	if !ok {
+		c = &DecisionCounts{}
+		s.byExt[ext] = c
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStats_Record(t *testing.T) {
+	t.Run("should count decisions and bytes", func(t *testing.T) {
+		stats := NewSessionStats(time.Now())
+
+		stats.RecordKeep("a.jpg", 100)
+		stats.RecordKeep("b.JPG", 50)
+		stats.RecordDelete("c.log", 30)
+		stats.RecordSkip("d.txt")
+		stats.RecordFailure("e")
+
+		if stats.Kept != 2 {
+			t.Errorf("Expected 2 kept, got %d", stats.Kept)
+		}
+		if stats.Deleted != 1 {
+			t.Errorf("Expected 1 deleted, got %d", stats.Deleted)
+		}
+		if stats.Skipped != 1 {
+			t.Errorf("Expected 1 skipped, got %d", stats.Skipped)
+		}
+		if stats.Failed != 1 {
+			t.Errorf("Expected 1 failed, got %d", stats.Failed)
+		}
+		if stats.Total() != 5 {
+			t.Errorf("Expected 5 decisions, got %d", stats.Total())
+		}
+		if stats.BytesMoved != 150 {
+			t.Errorf("Expected 150 bytes moved, got %d", stats.BytesMoved)
+		}
+		if stats.BytesFreed != 30 {
+			t.Errorf("Expected 30 bytes freed, got %d", stats.BytesFreed)
+		}
+	})
+
+	t.Run("should group decisions by lowercase extension", func(t *testing.T) {
+		stats := NewSessionStats(time.Now())
+
+		stats.RecordKeep("a.jpg", 0)
+		stats.RecordDelete("b.JPG", 0)
+		stats.RecordSkip("README")
+
+		exts := stats.Extensions()
+		if len(exts) != 2 || exts[0] != NoExtension || exts[1] != ".jpg" {
+			t.Fatalf("Expected [%s .jpg], got %v", NoExtension, exts)
+		}
+
+		jpg := stats.ByExtension(".jpg")
+		if jpg.Kept != 1 || jpg.Deleted != 1 {
+			t.Errorf("Expected 1 kept and 1 deleted .jpg, got %+v", jpg)
+		}
+		if stats.ByExtension(NoExtension).Skipped != 1 {
+			t.Error("Expected file without extension to be counted")
+		}
+		if stats.ByExtension(".png").Total() != 0 {
+			t.Error("Expected zero counts for unseen extension")
+		}
+	})
+}
+
+func TestSessionStats_Withdraw(t *testing.T) {
+	t.Run("should undo skip and failure of redecided files", func(t *testing.T) {
+		stats := NewSessionStats(time.Now())
+
+		stats.RecordSkip("a.jpg")
+		stats.RecordFailure("b.jpg")
+		stats.Withdraw("a.jpg", StatusSkipped)
+		stats.Withdraw("b.jpg", StatusFailed)
+		stats.Withdraw("c.jpg", StatusPending)
+
+		if stats.Skipped != 0 || stats.Failed != 0 {
+			t.Errorf("Expected no skips or failures, got %+v", stats.DecisionCounts)
+		}
+		if stats.ByExtension(".jpg").Total() != 0 {
+			t.Errorf("Expected empty .jpg breakdown, got %+v", stats.ByExtension(".jpg"))
+		}
+	})
+}
+
+func TestSessionStats_Timing(t *testing.T) {
+	t.Run("should measure elapsed time until finish", func(t *testing.T) {
+		start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		stats := NewSessionStats(start)
+
+		stats.RecordKeep("a", 0)
+		stats.RecordSkip("b")
+		stats.Finish(start.Add(10 * time.Second))
+		stats.Finish(start.Add(time.Hour))
+
+		if stats.Elapsed() != 10*time.Second {
+			t.Errorf("Expected 10s elapsed, got %v", stats.Elapsed())
+		}
+		if stats.AverageDecision() != 5*time.Second {
+			t.Errorf("Expected 5s per decision, got %v", stats.AverageDecision())
+		}
+	})
+
+	t.Run("should return zero average without decisions", func(t *testing.T) {
+		stats := NewSessionStats(time.Now())
+
+		if stats.AverageDecision() != 0 {
+			t.Errorf("Expected zero average, got %v", stats.AverageDecision())
+		}
+	})
+}
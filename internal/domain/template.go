@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultDateLayout formats dates in templates without an explicit layout.
+const DefaultDateLayout = "2006-01-02"
+
+// NameVars holds the values substituted into a name template.
+type NameVars struct {
+	Name    string // Original file name
+	ModTime time.Time
+	Counter int
+	Parent  string    // Name of the directory holding the file
+	Date    time.Time // Capture date; ModTime is used when zero
+}
+
+// ExpandName substitutes placeholders in a name template:
+// {name}, {ext}, {mtime:LAYOUT}, {date:LAYOUT}, {year}, {month}, {day},
+// {counter:WIDTH} and {parent}. Layout and width are optional.
+// Returns error for unknown placeholders.
+func ExpandName(tmpl string, v NameVars) (string, error) {
+	var out strings.Builder
+
+	for rest := tmpl; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:open])
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed placeholder in %q", tmpl)
+		}
+
+		value, err := expandPlaceholder(rest[open+1:open+end], v)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(value)
+		rest = rest[open+end+1:]
+	}
+
+	return out.String(), nil
+}
+
+func expandPlaceholder(placeholder string, v NameVars) (string, error) {
+	name, arg, hasArg := strings.Cut(placeholder, ":")
+
+	switch name {
+	case "name":
+		return strings.TrimSuffix(v.Name, filepath.Ext(v.Name)), nil
+	case "ext":
+		return strings.TrimPrefix(filepath.Ext(v.Name), "."), nil
+	case "parent":
+		return v.Parent, nil
+	case "mtime":
+		if !hasArg {
+			arg = DefaultDateLayout
+		}
+		return v.ModTime.Format(arg), nil
+	case "date":
+		if !hasArg {
+			arg = DefaultDateLayout
+		}
+		return v.date().Format(arg), nil
+	case "year":
+		return v.date().Format("2006"), nil
+	case "month":
+		return v.date().Format("01"), nil
+	case "day":
+		return v.date().Format("02"), nil
+	case "counter":
+		if !hasArg {
+			return strconv.Itoa(v.Counter), nil
+		}
+		width, err := strconv.Atoi(arg)
+		if err != nil || width < 1 {
+			return "", fmt.Errorf("invalid counter width: %s", arg)
+		}
+		return fmt.Sprintf("%0*d", width, v.Counter), nil
+	}
+
+	return "", fmt.Errorf("unknown placeholder: {%s}", placeholder)
+}
+
+// date returns the capture date, falling back to the modification time.
+func (v NameVars) date() time.Time {
+	if v.Date.IsZero() {
+		return v.ModTime
+	}
+	return v.Date
+}
+
+// ValidateName checks that name can be used as a single file name.
+// Rejects empty names, path separators and control characters.
+func ValidateName(name string) error {
+	switch name {
+	case "":
+		return errors.New("file name is empty")
+	case ".", "..":
+		return fmt.Errorf("invalid file name: %s", name)
+	}
+
+	for _, r := range name {
+		if r == '/' || r == '\\' || unicode.IsControl(r) {
+			return fmt.Errorf("file name contains illegal character %q", r)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandName(t *testing.T) {
+	vars := NameVars{
+		Name:    "IMG_2034.jpg",
+		ModTime: time.Date(2024, 6, 12, 15, 4, 5, 0, time.UTC),
+		Counter: 7,
+		Parent:  "holiday",
+	}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"{mtime:2006-01-02}_beach.{ext}", "2024-06-12_beach.jpg"},
+		{"{mtime}_{name}.{ext}", "2024-06-12_IMG_2034.jpg"},
+		{"{parent}-{counter}.{ext}", "holiday-7.jpg"},
+		{"{parent}_{counter:3}.{ext}", "holiday_007.jpg"},
+		{"{mtime:15h04}", "15h04"},
+		{"plain.jpg", "plain.jpg"},
+		{"{year}/{month}/{day}", "2024/06/12"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should expand "+tt.tmpl, func(t *testing.T) {
+			got, err := ExpandName(tt.tmpl, vars)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("should prefer the capture date", func(t *testing.T) {
+		v := vars
+		v.Date = time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		got, err := ExpandName("{year}/{date:Jan}/{mtime:2006}", v)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != "2019/Jan/2024" {
+			t.Errorf("Expected %q, got %q", "2019/Jan/2024", got)
+		}
+	})
+
+	for _, tmpl := range []string{"{size}", "{counter:x}", "{name"} {
+		t.Run("should return error for "+tmpl, func(t *testing.T) {
+			if _, err := ExpandName(tmpl, vars); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"photo.jpg", "2024-06-12 beach.jpg", ".hidden"} {
+		t.Run("should accept "+name, func(t *testing.T) {
+			if err := ValidateName(name); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	for _, name := range []string{"", ".", "..", "a/b.jpg", `a\b.jpg`, "a\nb"} {
+		t.Run("should reject "+name, func(t *testing.T) {
+			if err := ValidateName(name); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -3,18 +3,39 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/BurntSushi/toml"
+	"github.com/rycln/filer/internal/domain"
 	flag "github.com/spf13/pflag"
 )
 
 type Config struct {
-	Source  string
-	Target  string
-	Pattern string
+	Source     string                       `toml:"source"`
+	Target     string                       `toml:"target"`
+	TargetTmpl string                       `toml:"target-template"`
+	KeepMode   string                       `toml:"keep-mode"`
+	Pattern    string                       `toml:"pattern"`
+	Exif       []string                     `toml:"exif"`
+	Groups     []string                     `toml:"groups"`
+	Report     string                       `toml:"report"`
+	Theme      string                       `toml:"theme"`
+	Plain      bool                         `toml:"plain"`
+	List       bool                         `toml:"list"`
+	Watch      bool                         `toml:"watch"`
+	Journal    string                       `toml:"journal"`
+	Verify     bool                         `toml:"verify"`
+	Quota      string                       `toml:"target-quota"`
+	Keys       map[string][]string          `toml:"keys"`
+	Themes     map[string]map[string]string `toml:"themes"`
+	ConfigFile string                       `toml:"-"`
+	StateDir   string                       `toml:"-"` // journal and locks of read-only sources
 }
 
 type ConfigBuilder struct {
 	cfg *Config
+	err error
 }
 
 func NewConfigBuilder() *ConfigBuilder {
@@ -26,14 +47,81 @@ func NewConfigBuilder() *ConfigBuilder {
 func (b *ConfigBuilder) WithFlagParsing() *ConfigBuilder {
 	flag.StringVarP(&b.cfg.Source, "source", "s", ".", "Source directory (default: current)")
 	flag.StringVarP(&b.cfg.Target, "target", "t", "", "Target directory for kept files (default: keep in place)")
+	flag.StringVar(&b.cfg.TargetTmpl, "target-template", "", "Target directory template for kept files, e.g. '~/Archive/{year}/{month}'")
+	flag.StringVar(&b.cfg.KeepMode, "keep-mode", "move", "What keeping a file does: move, copy, symlink or hardlink")
 	flag.StringVarP(&b.cfg.Pattern, "pattern", "p", "", "Regular expression pattern to filter files")
+	flag.StringArrayVar(&b.cfg.Exif, "exif", nil, "Only sort files whose metadata matches FIELD=VALUE, e.g. camera=pixel or gps=no (repeatable)")
+	flag.StringArrayVar(&b.cfg.Groups, "group", nil, "Treat files sharing a stem with these extensions as one, e.g. jpg,arw,xmp (repeatable)")
+	flag.StringVar(&b.cfg.Report, "report", "", "Write session statistics as JSON to this file")
+	flag.StringVar(&b.cfg.Theme, "theme", "", "Colour theme: auto, dark, light, high-contrast, monochrome or a theme from the config file")
+	flag.BoolVar(&b.cfg.Plain, "plain", false, "Plain text output without colours or emoji (default: on when TERM=dumb)")
+	flag.BoolVar(&b.cfg.List, "list", false, "Show the file list pane on start (toggle with tab)")
+	flag.BoolVar(&b.cfg.Watch, "watch", false, "Keep running and add files arriving in the source directory")
+	flag.BoolVar(&b.cfg.Verify, "verify", false, "Compare SHA-256 checksums of copies between file systems before removing the original")
+	flag.StringVar(&b.cfg.Quota, "target-quota", "", "Most data to keep in the target this session, e.g. 50GB or 4GiB")
+	flag.StringVar(&b.cfg.Journal, "journal", "", "Log of copies between file systems, repaired on start after a crash (default: filer/journal.jsonl in user state dir)")
+	flag.StringVarP(&b.cfg.ConfigFile, "config", "c", "", "Config file (default: filer/config.toml in user config dir)")
 
 	flag.Parse()
 
 	return b
 }
 
+// WithConfigFile loads settings from a TOML config file.
+// Uses --config or the default path if present; command line flags win.
+func (b *ConfigBuilder) WithConfigFile() *ConfigBuilder {
+	path := b.cfg.ConfigFile
+	if path == "" {
+		path = defaultConfigPath()
+		if _, err := os.Stat(path); err != nil {
+			return b
+		}
+	}
+
+	var file Config
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		b.err = fmt.Errorf("failed to read config file %s: %w", path, err)
+		return b
+	}
+
+	setUnlessFlagged(&b.cfg.Source, file.Source, "source")
+	setUnlessFlagged(&b.cfg.Target, file.Target, "target")
+	setUnlessFlagged(&b.cfg.TargetTmpl, file.TargetTmpl, "target-template")
+	setUnlessFlagged(&b.cfg.KeepMode, file.KeepMode, "keep-mode")
+	setUnlessFlagged(&b.cfg.Pattern, file.Pattern, "pattern")
+	if !flagChanged("exif") && len(file.Exif) > 0 {
+		b.cfg.Exif = file.Exif
+	}
+	if !flagChanged("group") && len(file.Groups) > 0 {
+		b.cfg.Groups = file.Groups
+	}
+	setUnlessFlagged(&b.cfg.Report, file.Report, "report")
+	setUnlessFlagged(&b.cfg.Theme, file.Theme, "theme")
+	setUnlessFlagged(&b.cfg.Journal, file.Journal, "journal")
+	setUnlessFlagged(&b.cfg.Quota, file.Quota, "target-quota")
+	if !flagChanged("plain") {
+		b.cfg.Plain = b.cfg.Plain || file.Plain
+	}
+	if !flagChanged("list") {
+		b.cfg.List = b.cfg.List || file.List
+	}
+	if !flagChanged("watch") {
+		b.cfg.Watch = b.cfg.Watch || file.Watch
+	}
+	if !flagChanged("verify") {
+		b.cfg.Verify = b.cfg.Verify || file.Verify
+	}
+	b.cfg.Keys = file.Keys
+	b.cfg.Themes = file.Themes
+
+	return b
+}
+
 func (b *ConfigBuilder) Build() (*Config, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
 	if b.cfg.Source == "" {
 		return nil, fmt.Errorf("source directory is required")
 	}
@@ -42,5 +130,95 @@ func (b *ConfigBuilder) Build() (*Config, error) {
 		return nil, fmt.Errorf("source directory does not exist: %s", b.cfg.Source)
 	}
 
+	if b.cfg.TargetTmpl != "" {
+		if b.cfg.Target != "" {
+			return nil, fmt.Errorf("target and target template cannot be used together")
+		}
+		tmpl, err := expandHome(b.cfg.TargetTmpl)
+		if err != nil {
+			return nil, err
+		}
+		b.cfg.TargetTmpl = tmpl
+	}
+
+	if b.cfg.Quota != "" {
+		if b.cfg.Target == "" && b.cfg.TargetTmpl == "" {
+			return nil, fmt.Errorf("target quota requires a target directory")
+		}
+		quota, err := domain.ParseSize(b.cfg.Quota)
+		if err != nil {
+			return nil, err
+		}
+		if quota <= 0 {
+			return nil, fmt.Errorf("target quota must be above zero, got %q", b.cfg.Quota)
+		}
+	}
+
+	b.cfg.StateDir = defaultStateDir()
+	if b.cfg.Journal == "" && b.cfg.StateDir != "" {
+		b.cfg.Journal = filepath.Join(b.cfg.StateDir, "journal.jsonl")
+	}
+
+	switch b.cfg.KeepMode {
+	case "", "move":
+	case "copy", "symlink", "hardlink":
+		if b.cfg.Target == "" && b.cfg.TargetTmpl == "" {
+			return nil, fmt.Errorf("keep mode %s requires a target directory", b.cfg.KeepMode)
+		}
+	default:
+		return nil, fmt.Errorf("unknown keep mode: %s", b.cfg.KeepMode)
+	}
+
 	return b.cfg, nil
 }
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "filer", "config.toml")
+}
+
+// defaultStateDir returns filer in $XDG_STATE_HOME, falling back to
+// ~/.local/state.
+func defaultStateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "filer")
+}
+
+// expandHome replaces a leading "~" with the user home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %s: %w", path, err)
+	}
+	return home + path[1:], nil
+}
+
+// setUnlessFlagged applies a config file value unless the flag was given.
+func setUnlessFlagged(dst *string, value, name string) {
+	if value == "" {
+		return
+	}
+	if flagChanged(name) {
+		return
+	}
+	*dst = value
+}
+
+func flagChanged(name string) bool {
+	f := flag.Lookup(name)
+	return f != nil && f.Changed
+}
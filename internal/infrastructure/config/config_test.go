@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	flag "github.com/spf13/pflag"
@@ -67,6 +68,121 @@ func TestConfigBuilder_WithFlagParsing(t *testing.T) {
 		if builder.cfg.Pattern != "" {
 			t.Errorf("Expected empty pattern, got %s", builder.cfg.Pattern)
 		}
+		if builder.cfg.Report != "" {
+			t.Errorf("Expected empty report, got %s", builder.cfg.Report)
+		}
+	})
+}
+
+func TestConfigBuilder_WithConfigFile(t *testing.T) {
+	t.Run("should load values and key bindings from config file", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_config")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		path := filepath.Join(tempDir, "config.toml")
+		content := "target = \"/from/file\"\npattern = \"\\\\.jpg$\"\ntheme = \"mine\"\nplain = true\nlist = true\nwatch = true\nverify = true\ntarget-quota = \"50GB\"\nexif = [\"gps=no\"]\ngroups = [\"jpg,arw\"]\n\n" +
+			"[keys]\nkeep = [\"y\", \"enter\"]\n\n[themes.mine]\nbase = \"light\"\ntitle = \"#ff0000\"\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		flag.CommandLine = flag.NewFlagSet("test", flag.ExitOnError)
+		builder := NewConfigBuilder()
+		builder.cfg.ConfigFile = path
+
+		builder.WithConfigFile()
+
+		if builder.err != nil {
+			t.Fatalf("Expected no error, got %v", builder.err)
+		}
+		if builder.cfg.Target != "/from/file" {
+			t.Errorf("Expected target /from/file, got %s", builder.cfg.Target)
+		}
+		if builder.cfg.Pattern != "\\.jpg$" {
+			t.Errorf("Expected pattern \\.jpg$, got %s", builder.cfg.Pattern)
+		}
+		keys := builder.cfg.Keys["keep"]
+		if len(keys) != 2 || keys[0] != "y" || keys[1] != "enter" {
+			t.Errorf("Expected keep keys [y enter], got %v", keys)
+		}
+		if !builder.cfg.Plain {
+			t.Error("Expected plain mode from config file")
+		}
+		if !builder.cfg.List {
+			t.Error("Expected list pane from config file")
+		}
+		if !builder.cfg.Watch {
+			t.Error("Expected watch mode from config file")
+		}
+		if !builder.cfg.Verify {
+			t.Error("Expected verification from config file")
+		}
+		if builder.cfg.Quota != "50GB" {
+			t.Errorf("Expected target quota 50GB, got %s", builder.cfg.Quota)
+		}
+		if len(builder.cfg.Exif) != 1 || builder.cfg.Exif[0] != "gps=no" {
+			t.Errorf("Expected exif conditions [gps=no], got %v", builder.cfg.Exif)
+		}
+		if len(builder.cfg.Groups) != 1 || builder.cfg.Groups[0] != "jpg,arw" {
+			t.Errorf("Expected group rules [jpg,arw], got %v", builder.cfg.Groups)
+		}
+		if builder.cfg.Theme != "mine" {
+			t.Errorf("Expected theme mine, got %s", builder.cfg.Theme)
+		}
+		if builder.cfg.Themes["mine"]["title"] != "#ff0000" {
+			t.Errorf("Expected custom theme title colour, got %v", builder.cfg.Themes)
+		}
+	})
+
+	t.Run("should let command line flags override config file", func(t *testing.T) {
+		oldArgs := os.Args
+		defer func() { os.Args = oldArgs }()
+
+		tempDir, err := os.MkdirTemp("", "test_config")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		path := filepath.Join(tempDir, "config.toml")
+		content := "target = \"/from/file\"\npattern = \"file\"\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		os.Args = []string{"test", "--config", path, "--target", "/from/flag"}
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+		builder := NewConfigBuilder().WithFlagParsing().WithConfigFile()
+
+		if builder.err != nil {
+			t.Fatalf("Expected no error, got %v", builder.err)
+		}
+		if builder.cfg.Target != "/from/flag" {
+			t.Errorf("Expected target /from/flag, got %s", builder.cfg.Target)
+		}
+		if builder.cfg.Pattern != "file" {
+			t.Errorf("Expected pattern file, got %s", builder.cfg.Pattern)
+		}
+	})
+
+	t.Run("should fail build when config file is missing", func(t *testing.T) {
+		flag.CommandLine = flag.NewFlagSet("test", flag.ExitOnError)
+		builder := NewConfigBuilder()
+		builder.cfg.Source = "."
+		builder.cfg.ConfigFile = "/nonexistent/config.toml"
+
+		config, err := builder.WithConfigFile().Build()
+
+		if err == nil {
+			t.Error("Expected error for missing config file")
+		}
+		if config != nil {
+			t.Error("Expected nil config when error occurs")
+		}
 	})
 }
 
@@ -162,6 +278,150 @@ func TestConfigBuilder_Build(t *testing.T) {
 	})
 }
 
+func TestConfigBuilder_Build_Journal(t *testing.T) {
+	t.Run("should default to the user state directory", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/state")
+
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+
+		config, err := builder.Build()
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.Journal != "/state/filer/journal.jsonl" {
+			t.Errorf("Expected journal in state dir, got %s", config.Journal)
+		}
+		if config.StateDir != "/state/filer" {
+			t.Errorf("Expected state dir /state/filer, got %s", config.StateDir)
+		}
+	})
+
+	t.Run("should keep a given journal", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.Journal = "/tmp/moves.jsonl"
+
+		config, err := builder.Build()
+
+		if err != nil || config.Journal != "/tmp/moves.jsonl" {
+			t.Errorf("Expected given journal, got %v (%v)", config, err)
+		}
+	})
+}
+
+func TestConfigBuilder_Build_KeepMode(t *testing.T) {
+	t.Run("should accept keep modes with target", func(t *testing.T) {
+		for _, mode := range []string{"", "move", "copy", "symlink", "hardlink"} {
+			builder := NewConfigBuilder()
+			builder.cfg.Source = t.TempDir()
+			builder.cfg.Target = "/some/target"
+			builder.cfg.KeepMode = mode
+
+			if _, err := builder.Build(); err != nil {
+				t.Errorf("Expected mode %q to be valid, got %v", mode, err)
+			}
+		}
+	})
+
+	t.Run("should require target for copy and links", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.KeepMode = "copy"
+
+		_, err := builder.Build()
+
+		expectedErr := "keep mode copy requires a target directory"
+		if err == nil || err.Error() != expectedErr {
+			t.Errorf("Expected error '%s', got '%v'", expectedErr, err)
+		}
+	})
+
+	t.Run("should reject unknown keep mode", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.KeepMode = "teleport"
+
+		_, err := builder.Build()
+
+		expectedErr := "unknown keep mode: teleport"
+		if err == nil || err.Error() != expectedErr {
+			t.Errorf("Expected error '%s', got '%v'", expectedErr, err)
+		}
+	})
+
+	t.Run("should require target for quota", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.Quota = "50GB"
+
+		_, err := builder.Build()
+
+		expectedErr := "target quota requires a target directory"
+		if err == nil || err.Error() != expectedErr {
+			t.Errorf("Expected error '%s', got '%v'", expectedErr, err)
+		}
+	})
+
+	t.Run("should reject invalid quota", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.Target = "/some/target"
+		builder.cfg.Quota = "50 parsecs"
+
+		if _, err := builder.Build(); err == nil {
+			t.Error("Expected error for invalid quota")
+		}
+	})
+
+	t.Run("should reject zero quota", func(t *testing.T) {
+		for _, quota := range []string{"0", "0B", "0.1B"} {
+			builder := NewConfigBuilder()
+			builder.cfg.Source = t.TempDir()
+			builder.cfg.Target = "/some/target"
+			builder.cfg.Quota = quota
+
+			if _, err := builder.Build(); err == nil {
+				t.Errorf("Expected error for quota %q", quota)
+			}
+		}
+	})
+}
+
+func TestConfigBuilder_Build_TargetTemplate(t *testing.T) {
+	t.Run("should expand home directory", func(t *testing.T) {
+		t.Setenv("HOME", "/home/tester")
+
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.TargetTmpl = "~/Archive/{year}/{month}"
+		builder.cfg.KeepMode = "copy"
+
+		cfg, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.TargetTmpl != "/home/tester/Archive/{year}/{month}" {
+			t.Errorf("Expected expanded template, got %s", cfg.TargetTmpl)
+		}
+	})
+
+	t.Run("should reject template together with target", func(t *testing.T) {
+		builder := NewConfigBuilder()
+		builder.cfg.Source = t.TempDir()
+		builder.cfg.Target = "/some/target"
+		builder.cfg.TargetTmpl = "/archive/{year}"
+
+		_, err := builder.Build()
+
+		expectedErr := "target and target template cannot be used together"
+		if err == nil || err.Error() != expectedErr {
+			t.Errorf("Expected error '%s', got '%v'", expectedErr, err)
+		}
+	})
+}
+
 func TestConfigBuilder_Integration(t *testing.T) {
 	t.Run("should build complete config with flag parsing and validation", func(t *testing.T) {
 		oldArgs := os.Args
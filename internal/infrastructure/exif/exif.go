@@ -0,0 +1,284 @@
+// Package exif reads EXIF metadata from JPEG, TIFF-based and HEIF image
+// files. Only the tags filer uses are decoded.
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNoExif is returned for files without image metadata.
+var ErrNoExif = errors.New("no exif data")
+
+// dateLayout is the EXIF date and time format.
+const dateLayout = "2006:01:02 15:04:05"
+
+// TIFF tags read by Decode.
+const (
+	tagImageWidth       = 0x0100
+	tagImageHeight      = 0x0101
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+	tagGPSLatitude      = 0x0002
+)
+
+// TIFF field types read by Decode.
+const (
+	typeASCII = 2
+	typeShort = 3
+	typeLong  = 4
+)
+
+// Tags holds decoded EXIF fields. Missing fields are left zero.
+type Tags struct {
+	DateTimeOriginal time.Time
+	Make             string
+	Model            string
+	Width            int
+	Height           int
+	Orientation      int // 1 to 8 as defined by EXIF
+	GPS              bool
+}
+
+// Camera returns make and model without the make repeated.
+func (t Tags) Camera() string {
+	if t.Make == "" || strings.HasPrefix(strings.ToLower(t.Model), strings.ToLower(t.Make)) {
+		return t.Model
+	}
+	if t.Model == "" {
+		return t.Make
+	}
+	return t.Make + " " + t.Model
+}
+
+// Rotated reports whether the orientation turns the image by 90 degrees.
+func (t Tags) Rotated() bool {
+	return t.Orientation >= 5 && t.Orientation <= 8
+}
+
+// ReadFile decodes EXIF metadata of the file at path.
+func ReadFile(path string) (Tags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
+
+// Decode reads EXIF metadata from a JPEG, TIFF-based or HEIF image.
+// Returns ErrNoExif when the data carries none.
+func Decode(r io.ReaderAt) (Tags, error) {
+	var magic [12]byte
+	n, _ := r.ReadAt(magic[:], 0)
+
+	switch {
+	case n >= 2 && magic[0] == 0xFF && magic[1] == 0xD8:
+		return decodeJPEG(io.NewSectionReader(r, 2, 1<<62))
+	case n >= 4 && (string(magic[:4]) == "II*\x00" || string(magic[:4]) == "MM\x00*"):
+		return decodeTIFF(r)
+	case n >= 12 && string(magic[4:8]) == "ftyp" && heifBrands[string(magic[8:12])]:
+		payload, err := heifExif(r)
+		if err != nil {
+			return Tags{}, err
+		}
+		return decodeTIFF(bytes.NewReader(payload))
+	}
+
+	return Tags{}, ErrNoExif
+}
+
+// decodeJPEG reads the EXIF APP1 segment and the frame dimensions.
+// Frame dimensions win over EXIF ones, which editors often leave stale.
+func decodeJPEG(r io.Reader) (Tags, error) {
+	var (
+		tags          Tags
+		found         bool
+		width, height int
+		header        [4]byte
+	)
+
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil || header[0] != 0xFF {
+			break
+		}
+
+		marker := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if length < 0 || marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			break
+		}
+
+		switch {
+		case marker == 0xE1 && !found && bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+			t, err := decodeTIFF(bytes.NewReader(segment[6:]))
+			if err == nil {
+				tags, found = t, true
+			}
+		case isSOF(marker) && length >= 5:
+			height = int(binary.BigEndian.Uint16(segment[1:]))
+			width = int(binary.BigEndian.Uint16(segment[3:]))
+		}
+	}
+
+	if !found && width == 0 {
+		return Tags{}, ErrNoExif
+	}
+	if width > 0 {
+		tags.Width, tags.Height = width, height
+	}
+	return tags, nil
+}
+
+// isSOF reports whether marker starts a frame holding image dimensions.
+func isSOF(marker byte) bool {
+	return marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC
+}
+
+// tiff reads IFD entries of a TIFF structure.
+type tiff struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+}
+
+// entry is a raw IFD entry.
+type entry struct {
+	typ   uint16
+	count uint32
+	value [4]byte
+}
+
+// maxEntries bounds IFD sizes to guard against corrupt files.
+const maxEntries = 1000
+
+func decodeTIFF(r io.ReaderAt) (Tags, error) {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return Tags{}, ErrNoExif
+	}
+
+	t := tiff{r: r}
+	switch string(header[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return Tags{}, ErrNoExif
+	}
+
+	ifd0, err := t.ifd(t.order.Uint32(header[4:]))
+	if err != nil {
+		return Tags{}, err
+	}
+
+	tags := Tags{
+		Make:        t.ascii(ifd0[tagMake]),
+		Model:       t.ascii(ifd0[tagModel]),
+		Width:       t.int(ifd0[tagImageWidth]),
+		Height:      t.int(ifd0[tagImageHeight]),
+		Orientation: t.int(ifd0[tagOrientation]),
+	}
+
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if exifIFD, err := t.ifd(t.order.Uint32(e.value[:])); err == nil {
+			tags.DateTimeOriginal = t.date(exifIFD[tagDateTimeOriginal])
+			if w, h := t.int(exifIFD[tagPixelXDimension]), t.int(exifIFD[tagPixelYDimension]); w > 0 && h > 0 {
+				tags.Width, tags.Height = w, h
+			}
+		}
+	}
+
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		if gpsIFD, err := t.ifd(t.order.Uint32(e.value[:])); err == nil {
+			_, tags.GPS = gpsIFD[tagGPSLatitude]
+		}
+	}
+
+	return tags, nil
+}
+
+// ifd reads the entries of the IFD at offset.
+func (t tiff) ifd(offset uint32) (map[uint16]entry, error) {
+	var n [2]byte
+	if _, err := t.r.ReadAt(n[:], int64(offset)); err != nil {
+		return nil, ErrNoExif
+	}
+	count := int(t.order.Uint16(n[:]))
+	if count > maxEntries {
+		return nil, ErrNoExif
+	}
+
+	raw := make([]byte, count*12)
+	if _, err := t.r.ReadAt(raw, int64(offset)+2); err != nil {
+		return nil, ErrNoExif
+	}
+
+	entries := make(map[uint16]entry, count)
+	for i := range count {
+		b := raw[i*12:]
+		e := entry{
+			typ:   t.order.Uint16(b[2:]),
+			count: t.order.Uint32(b[4:]),
+		}
+		copy(e.value[:], b[8:12])
+		entries[t.order.Uint16(b)] = e
+	}
+
+	return entries, nil
+}
+
+// ascii returns the string value of an ASCII entry.
+func (t tiff) ascii(e entry) string {
+	if e.typ != typeASCII || e.count > 1<<16 {
+		return ""
+	}
+
+	data := e.value[:min(e.count, 4)]
+	if e.count > 4 {
+		data = make([]byte, e.count)
+		if _, err := t.r.ReadAt(data, int64(t.order.Uint32(e.value[:]))); err != nil {
+			return ""
+		}
+	}
+
+	return strings.TrimRight(string(data), "\x00 ")
+}
+
+// int returns the first value of a SHORT or LONG entry.
+func (t tiff) int(e entry) int {
+	switch e.typ {
+	case typeShort:
+		return int(t.order.Uint16(e.value[:]))
+	case typeLong:
+		return int(t.order.Uint32(e.value[:]))
+	}
+	return 0
+}
+
+// date parses an EXIF date entry in local time.
+// Returns zero time for missing or malformed values.
+func (t tiff) date(e entry) time.Time {
+	d, err := time.ParseInLocation(dateLayout, t.ascii(e), time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return d
+}
@@ -0,0 +1,238 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// photo lists the fields written by tiffData.
+type photo struct {
+	make, model, date string
+	orientation       uint16
+	width, height     uint32
+	gps               bool
+}
+
+// tiffData builds a TIFF structure with IFD0, Exif and GPS IFDs.
+// Strings that do not fit an entry are stored after the IFDs.
+func tiffData(order binary.ByteOrder, p photo) []byte {
+	const ifd0, exifIFD, gpsIFD, dataStart = 8, 74, 116, 134
+
+	var head, data bytes.Buffer
+	w := func(v any) { binary.Write(&head, order, v) }
+	entry := func(tag, typ uint16, count, value uint32) {
+		w([]uint16{tag, typ})
+		w([]uint32{count, value})
+	}
+	ascii := func(tag uint16, s string) {
+		s += "\x00"
+		if len(s) <= 4 {
+			w([]uint16{tag, typeASCII})
+			w(uint32(len(s)))
+			head.WriteString((s + "\x00\x00\x00")[:4])
+			return
+		}
+		entry(tag, typeASCII, uint32(len(s)), uint32(dataStart+data.Len()))
+		data.WriteString(s)
+	}
+
+	if order == binary.LittleEndian {
+		head.WriteString("II")
+	} else {
+		head.WriteString("MM")
+	}
+	w(uint16(42))
+	w(uint32(ifd0))
+
+	w(uint16(5))
+	ascii(tagMake, p.make)
+	ascii(tagModel, p.model)
+	w([]uint16{tagOrientation, typeShort})
+	w(uint32(1))
+	w([]uint16{p.orientation, 0})
+	entry(tagExifIFD, typeLong, 1, exifIFD)
+	entry(tagGPSIFD, typeLong, 1, gpsIFD)
+	w(uint32(0))
+
+	w(uint16(3))
+	ascii(tagDateTimeOriginal, p.date)
+	entry(tagPixelXDimension, typeLong, 1, p.width)
+	entry(tagPixelYDimension, typeLong, 1, p.height)
+	w(uint32(0))
+
+	// GPS IFDs without coordinates only carry the version.
+	w(uint16(1))
+	if p.gps {
+		entry(tagGPSLatitude, 5, 3, 0)
+	} else {
+		entry(0x0000, 1, 4, 0)
+	}
+	w(uint32(0))
+
+	return append(head.Bytes(), data.Bytes()...)
+}
+
+// jpegData wraps a TIFF structure into a JPEG APP1 segment followed by
+// a frame of the given size.
+func jpegData(payload []byte, width, height uint16) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0xFF, 0xD8})
+
+	// An unrelated APP0 segment comes first in most files.
+	b.Write([]byte{0xFF, 0xE0, 0x00, 0x04, 'J', 'F'})
+
+	if payload != nil {
+		segment := append([]byte("Exif\x00\x00"), payload...)
+		b.Write([]byte{0xFF, 0xE1})
+		binary.Write(&b, binary.BigEndian, uint16(len(segment)+2))
+		b.Write(segment)
+	}
+
+	b.Write([]byte{0xFF, 0xC0, 0x00, 0x08, 0x08})
+	binary.Write(&b, binary.BigEndian, []uint16{height, width})
+	b.WriteByte(0)
+
+	b.Write([]byte{0xFF, 0xD9})
+	return b.Bytes()
+}
+
+// heifData stores a TIFF structure as the Exif item of a HEIF file.
+func heifData(payload []byte) []byte {
+	box := func(typ string, parts ...[]byte) []byte {
+		content := bytes.Join(parts, nil)
+		b := binary.BigEndian.AppendUint32(nil, uint32(len(content)+8))
+		return append(append(b, typ...), content...)
+	}
+	be := func(v ...any) []byte {
+		var b bytes.Buffer
+		for _, x := range v {
+			binary.Write(&b, binary.BigEndian, x)
+		}
+		return b.Bytes()
+	}
+
+	item := append(be(uint32(6)), "Exif\x00\x00"...)
+	item = append(item, payload...)
+
+	ftyp := box("ftyp", []byte("heic"), be(uint32(0)))
+	iinf := box("iinf", be(uint32(0), uint16(1)),
+		box("infe", be(uint32(2<<24), uint16(1), uint16(0)), []byte("Exif\x00")))
+	meta := func(offset uint32) []byte {
+		iloc := box("iloc", be(uint32(0), uint16(0x4400), uint16(1),
+			uint16(1), uint16(0), uint16(1), offset, uint32(len(item))))
+		return box("meta", be(uint32(0)), iinf, iloc)
+	}
+
+	offset := uint32(len(ftyp) + len(meta(0)) + 8)
+	return bytes.Join([][]byte{ftyp, meta(offset), box("mdat", item)}, nil)
+}
+
+func TestDecode(t *testing.T) {
+	p := photo{
+		make:        "Google",
+		model:       "Pixel 7",
+		date:        "2023:07:14 18:30:05",
+		orientation: 6,
+		width:       4080,
+		height:      3072,
+		gps:         true,
+	}
+	want := Tags{
+		DateTimeOriginal: time.Date(2023, 7, 14, 18, 30, 5, 0, time.Local),
+		Make:             "Google",
+		Model:            "Pixel 7",
+		Width:            4080,
+		Height:           3072,
+		Orientation:      6,
+		GPS:              true,
+	}
+
+	tests := map[string][]byte{
+		"jpeg little endian": jpegData(tiffData(binary.LittleEndian, p), 4080, 3072),
+		"jpeg big endian":    jpegData(tiffData(binary.BigEndian, p), 4080, 3072),
+		"tiff raw":           tiffData(binary.LittleEndian, p),
+		"heif":               heifData(tiffData(binary.BigEndian, p)),
+	}
+
+	for name, data := range tests {
+		t.Run("should read tags from "+name, func(t *testing.T) {
+			tags, err := Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !tags.DateTimeOriginal.Equal(want.DateTimeOriginal) {
+				t.Errorf("Expected date %v, got %v", want.DateTimeOriginal, tags.DateTimeOriginal)
+			}
+			tags.DateTimeOriginal = want.DateTimeOriginal
+			if tags != want {
+				t.Errorf("Expected %+v, got %+v", want, tags)
+			}
+		})
+	}
+
+	t.Run("should prefer jpeg frame size over exif", func(t *testing.T) {
+		tags, err := Decode(bytes.NewReader(jpegData(tiffData(binary.LittleEndian, p), 800, 600)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tags.Width != 800 || tags.Height != 600 {
+			t.Errorf("Expected 800x600, got %dx%d", tags.Width, tags.Height)
+		}
+	})
+
+	t.Run("should read frame size of jpeg without exif", func(t *testing.T) {
+		tags, err := Decode(bytes.NewReader(jpegData(nil, 640, 480)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tags.Width != 640 || tags.Height != 480 || tags.Camera() != "" {
+			t.Errorf("Expected only 640x480, got %+v", tags)
+		}
+	})
+
+	t.Run("should leave missing fields zero", func(t *testing.T) {
+		tags, err := Decode(bytes.NewReader(tiffData(binary.BigEndian, photo{date: "0000:00:00 00:00:00"})))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !tags.DateTimeOriginal.IsZero() || tags.GPS || tags.Camera() != "" {
+			t.Errorf("Expected empty tags, got %+v", tags)
+		}
+	})
+
+	for name, data := range map[string][]byte{
+		"text file":      []byte("hello world"),
+		"jpeg w/o exif":  {0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F', 0xFF, 0xD9},
+		"truncated tiff": tiffData(binary.LittleEndian, p)[:20],
+		"truncated heif": heifData(tiffData(binary.BigEndian, p))[:60],
+		"empty":          {},
+	} {
+		t.Run("should return ErrNoExif for "+name, func(t *testing.T) {
+			if _, err := Decode(bytes.NewReader(data)); !errors.Is(err, ErrNoExif) {
+				t.Errorf("Expected ErrNoExif, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTags_Camera(t *testing.T) {
+	tests := []struct {
+		make, model, want string
+	}{
+		{"Canon", "Canon EOS R5", "Canon EOS R5"},
+		{"Google", "Pixel 7", "Google Pixel 7"},
+		{"", "Pixel 7", "Pixel 7"},
+		{"SONY", "", "SONY"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should combine "+tt.make+" and "+tt.model, func(t *testing.T) {
+			if got := (Tags{Make: tt.make, Model: tt.model}).Camera(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
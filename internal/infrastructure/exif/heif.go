@@ -0,0 +1,211 @@
+package exif
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// heifBrands are ftyp major brands of HEIF based images.
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"mif1": true, "msf1": true, "avif": true,
+}
+
+// maxMetaBox bounds the size of the HEIF meta box read into memory.
+const maxMetaBox = 1 << 20
+
+// heifExif returns the TIFF payload of the Exif item of a HEIF file.
+func heifExif(r io.ReaderAt) ([]byte, error) {
+	meta, err := topLevelBox(r, "meta")
+	if err != nil {
+		return nil, err
+	}
+
+	// meta is a full box: skip version and flags.
+	if len(meta) < 4 {
+		return nil, ErrNoExif
+	}
+
+	id, ok := exifItem(firstBox(meta[4:], "iinf"))
+	if !ok {
+		return nil, ErrNoExif
+	}
+	offset, length, ok := itemLocation(firstBox(meta[4:], "iloc"), id)
+	if !ok || length < 8 || length > maxMetaBox {
+		return nil, ErrNoExif
+	}
+
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, int64(offset)); err != nil {
+		return nil, ErrNoExif
+	}
+
+	// The item starts with the offset of the TIFF header.
+	skip := uint64(binary.BigEndian.Uint32(data)) + 4
+	if skip >= length {
+		return nil, ErrNoExif
+	}
+	return data[skip:], nil
+}
+
+// topLevelBox returns the payload of the first top-level box of type typ.
+func topLevelBox(r io.ReaderAt, typ string) ([]byte, error) {
+	var header [16]byte
+	for offset := int64(0); ; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return nil, ErrNoExif
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		start := offset + 8
+		if size == 1 {
+			if _, err := r.ReadAt(header[8:], offset+8); err != nil {
+				return nil, ErrNoExif
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:]))
+			start += 8
+		}
+		if size < start-offset {
+			return nil, ErrNoExif
+		}
+
+		if string(header[4:8]) == typ {
+			if size > maxMetaBox {
+				return nil, ErrNoExif
+			}
+			payload := make([]byte, size-(start-offset))
+			if _, err := r.ReadAt(payload, start); err != nil {
+				return nil, ErrNoExif
+			}
+			return payload, nil
+		}
+		offset += size
+	}
+}
+
+// firstBox returns the payload of the first box of type typ in data.
+func firstBox(data []byte, typ string) []byte {
+	if found := allBoxes(data, typ); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// reader reads big-endian fields and remembers running out of data.
+type reader struct {
+	b  []byte
+	ok bool
+}
+
+// uint reads an n byte unsigned integer. Zero sized fields read as 0.
+func (r *reader) uint(n int) uint64 {
+	if !r.ok || n > len(r.b) {
+		r.ok = false
+		return 0
+	}
+	var v uint64
+	for _, c := range r.b[:n] {
+		v = v<<8 | uint64(c)
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// exifItem returns the ID of the Exif item listed in an iinf box.
+func exifItem(iinf []byte) (uint64, bool) {
+	r := &reader{b: iinf, ok: true}
+	version := r.uint(1)
+	r.uint(3)
+	if version == 0 {
+		r.uint(2)
+	} else {
+		r.uint(4)
+	}
+	if !r.ok {
+		return 0, false
+	}
+
+	for _, infe := range allBoxes(r.b, "infe") {
+		e := &reader{b: infe, ok: true}
+		version := e.uint(1)
+		e.uint(3)
+		if version < 2 {
+			continue
+		}
+		var id uint64
+		if version == 2 {
+			id = e.uint(2)
+		} else {
+			id = e.uint(4)
+		}
+		e.uint(2)
+		if e.ok && len(e.b) >= 4 && string(e.b[:4]) == "Exif" {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// allBoxes returns payloads of all boxes of type typ in data.
+func allBoxes(data []byte, typ string) [][]byte {
+	var found [][]byte
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		if size < 8 || size > uint64(len(data)) {
+			break
+		}
+		if string(data[4:8]) == typ {
+			found = append(found, data[8:size])
+		}
+		data = data[size:]
+	}
+	return found
+}
+
+// itemLocation returns file offset and length of item id in an iloc box.
+// Only items stored in the file itself with one extent are supported.
+func itemLocation(iloc []byte, id uint64) (offset, length uint64, ok bool) {
+	r := &reader{b: iloc, ok: true}
+	version := r.uint(1)
+	r.uint(3)
+
+	sizes := r.uint(2)
+	offsetSize := int(sizes >> 12)
+	lengthSize := int(sizes >> 8 & 0xF)
+	baseSize := int(sizes >> 4 & 0xF)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xF)
+	}
+
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count := r.uint(idSize)
+
+	for i := uint64(0); i < count && r.ok; i++ {
+		itemID := r.uint(idSize)
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = r.uint(2) & 0xF
+		}
+		r.uint(2)
+		base := r.uint(baseSize)
+		extents := r.uint(2)
+
+		for j := uint64(0); j < extents && r.ok; j++ {
+			r.uint(indexSize)
+			extentOffset := r.uint(offsetSize)
+			extentLength := r.uint(lengthSize)
+			if itemID == id && j == 0 {
+				offset, length = base+extentOffset, extentLength
+			}
+		}
+
+		if itemID == id {
+			return offset, length, r.ok && method == 0 && extents == 1
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,15 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dropCache evicts the cached pages of f, so the next read comes from
+// disk. f must be synced first; dirty pages are not dropped.
+func dropCache(f *os.File) error {
+	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// rangeChunk is how much copy_file_range copies between progress reports.
+const rangeChunk = 8 << 20
+
+// fastCopy copies source to dest in the kernel: a reflink clone where the
+// file system shares blocks between files, copy_file_range otherwise.
+// Returns the strategy used, or "" with nothing written when neither is
+// supported or the file system copies nothing.
+func fastCopy(ctx context.Context, source, dest *os.File, report func(int64)) (string, error) {
+	err := cloneFile(source, dest)
+	if err == nil {
+		info, err := dest.Stat()
+		if err != nil {
+			return "", err
+		}
+		report(info.Size())
+		return strategyReflink, nil
+	}
+	if !unsupported(err) {
+		return "", err
+	}
+
+	n, err := copyRange(ctx, source, dest, report)
+	if n == 0 && err != nil && unsupported(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		// Some file systems, such as sysfs and some FUSE and overlay
+		// mounts, copy nothing without an error.
+		info, err := source.Stat()
+		if err != nil {
+			return "", err
+		}
+		if info.Size() > 0 {
+			return "", nil
+		}
+	}
+	return strategyRange, nil
+}
+
+// cloneFile makes dest share the data blocks of source (FICLONE).
+// Supported by btrfs and XFS within one file system, also across
+// btrfs subvolumes.
+func cloneFile(source, dest *os.File) error {
+	return unix.IoctlFileClone(int(dest.Fd()), int(source.Fd()))
+}
+
+// copyRange copies source to dest with copy_file_range, which lets the
+// kernel or the file system copy without passing data through user space.
+func copyRange(ctx context.Context, source, dest *os.File, report func(int64)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := unix.CopyFileRange(int(source.Fd()), nil, int(dest.Fd()), nil, rangeChunk, 0)
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, nil
+		}
+		total += int64(n)
+		report(total)
+	}
+}
+
+// unsupported reports whether err means a copy strategy is not
+// available for these files rather than that copying failed.
+func unsupported(err error) bool {
+	for _, errno := range []unix.Errno{unix.EXDEV, unix.EOPNOTSUPP, unix.EINVAL, unix.ENOSYS, unix.ENOTTY, unix.EBADF} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
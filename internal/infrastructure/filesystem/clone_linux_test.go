@@ -0,0 +1,166 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_fastCopy(t *testing.T) {
+	t.Run("should copy in the kernel and name the strategy", func(t *testing.T) {
+		tempDir := t.TempDir()
+		content := bytes.Repeat([]byte("photo"), 1000)
+		source, dest := openPair(t, tempDir, content)
+
+		var copied int64
+		strategy, err := fastCopy(context.Background(), source, dest, func(n int64) { copied = n })
+
+		if err != nil {
+			t.Fatalf("Failed to copy: %v", err)
+		}
+		if strategy == "" {
+			t.Skip("No kernel copy support on this file system")
+		}
+		if strategy != strategyReflink && strategy != strategyRange {
+			t.Errorf("Unexpected strategy %q", strategy)
+		}
+		if copied != int64(len(content)) {
+			t.Errorf("Expected %d bytes reported, got %d", len(content), copied)
+		}
+		if got, _ := os.ReadFile(dest.Name()); !bytes.Equal(got, content) {
+			t.Error("Copied content doesn't match original")
+		}
+	})
+
+	t.Run("should leave the copy to the buffered path when nothing is copied", func(t *testing.T) {
+		source, dest := openPair(t, t.TempDir(), []byte("photo"))
+		// copy_file_range copies nothing from the end of the file, like
+		// file systems that return 0 without an error.
+		if _, err := source.Seek(0, io.SeekEnd); err != nil {
+			t.Fatalf("Failed to seek: %v", err)
+		}
+
+		strategy, err := fastCopy(context.Background(), source, dest, func(int64) {})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strategy == strategyReflink {
+			t.Skip("Reflinks ignore the file offset")
+		}
+		if strategy != "" {
+			t.Errorf("Expected no strategy, got %q", strategy)
+		}
+	})
+
+	t.Run("should stop copying when cancelled", func(t *testing.T) {
+		source, dest := openPair(t, t.TempDir(), []byte("photo"))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if n, err := copyRange(ctx, source, dest, func(int64) {}); err == nil || n != 0 {
+			t.Errorf("Expected cancelled copy, got %d bytes (%v)", n, err)
+		}
+	})
+}
+
+// benchSize is the size of the file copied by BenchmarkCopyStrategies.
+const benchSize = 64 << 20
+
+// BenchmarkCopyStrategies compares the copy strategies. Reflinks need a
+// file system that supports them; point FILER_BENCH_DIR at one, e.g. a
+// loop-mounted btrfs:
+//
+//	truncate -s 1G /tmp/bench.img && mkfs.btrfs /tmp/bench.img
+//	sudo mount -o loop /tmp/bench.img /mnt && sudo chown $USER /mnt
+//	FILER_BENCH_DIR=/mnt go test -bench CopyStrategies ./internal/infrastructure/filesystem
+func BenchmarkCopyStrategies(b *testing.B) {
+	dir := os.Getenv("FILER_BENCH_DIR")
+	if dir == "" {
+		dir = b.TempDir()
+	}
+	dir, err := os.MkdirTemp(dir, "filer-bench")
+	if err != nil {
+		b.Fatalf("Failed to create bench dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	strategies := map[string]func(source, dest *os.File) error{
+		strategyReflink: cloneFile,
+		strategyRange: func(source, dest *os.File) error {
+			_, err := copyRange(context.Background(), source, dest, func(int64) {})
+			return err
+		},
+		strategyBuffered: func(source, dest *os.File) error {
+			_, err := io.Copy(dest, &progressReader{ctx: context.Background(), r: source, report: func(int64) {}})
+			return err
+		},
+	}
+
+	sourcePath := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(sourcePath, bytes.Repeat([]byte{0xA5}, benchSize), 0644); err != nil {
+		b.Fatalf("Failed to create test file: %v", err)
+	}
+
+	for name, run := range strategies {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(benchSize)
+			for range b.N {
+				b.StopTimer()
+				source, err := os.Open(sourcePath)
+				if err != nil {
+					b.Fatalf("Failed to open source: %v", err)
+				}
+				dest, err := os.Create(filepath.Join(dir, "dest.bin"))
+				if err != nil {
+					b.Fatalf("Failed to create destination: %v", err)
+				}
+
+				b.StartTimer()
+				err = run(source, dest)
+				if err == nil {
+					err = dest.Sync()
+				}
+				b.StopTimer()
+
+				source.Close()
+				dest.Close()
+				if unsupported(err) {
+					b.Skipf("%s not supported in %s", name, dir)
+				}
+				if err != nil {
+					b.Fatalf("Failed to copy: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// openPair writes content to a source file in dir and opens it along
+// with an empty destination. Both are closed after the test.
+func openPair(t *testing.T, dir string, content []byte) (source, dest *os.File) {
+	t.Helper()
+
+	sourcePath := filepath.Join(dir, "source.bin")
+	if err := os.WriteFile(sourcePath, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to open source: %v", err)
+	}
+	t.Cleanup(func() { source.Close() })
+
+	dest, err = os.Create(filepath.Join(dir, "dest.bin"))
+	if err != nil {
+		t.Fatalf("Failed to create destination: %v", err)
+	}
+	t.Cleanup(func() { dest.Close() })
+
+	return source, dest
+}
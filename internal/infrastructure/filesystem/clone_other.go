@@ -0,0 +1,13 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"context"
+	"os"
+)
+
+// fastCopy is not available outside Linux; copies are buffered.
+func fastCopy(ctx context.Context, source, dest *os.File, report func(int64)) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,280 @@
+package filesystem
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rycln/filer/internal/infrastructure/lock"
+)
+
+// journal is an intent log of copies between file systems. A copy is
+// logged before it starts and again once it is finished, so copies cut
+// short by a crash can be repaired on the next start, after which the
+// journal is compacted to the copies still unfinished.
+type journal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// journalEntry is one line of the journal. Paths are absolute.
+type journalEntry struct {
+	Time     time.Time `json:"time"`
+	Op       string    `json:"op"` // "begin" or "done"
+	Source   string    `json:"source"`
+	Dest     string    `json:"dest"`
+	Temp     string    `json:"temp"`
+	Move     bool      `json:"move,omitempty"`     // source is removed after the copy
+	PID      int       `json:"pid,omitempty"`      // session that began the copy
+	Host     string    `json:"host,omitempty"`     // host of that session
+	Strategy string    `json:"strategy,omitempty"` // how the data was copied
+	SHA256   string    `json:"sha256,omitempty"`   // checksum of a verified copy
+	Error    string    `json:"error,omitempty"`    // why a finished copy failed
+}
+
+const (
+	opBegin = "begin"
+	opDone  = "done"
+)
+
+// Errors logged for copies interrupted by a crash.
+var (
+	errInterrupted = errors.New("interrupted, repaired on start")
+	errUnrepaired  = errors.New("interrupted, could not be repaired")
+)
+
+// begin logs a copy of sourcePath to destPath through tempPath.
+// The entry is synced to disk before the copy may start.
+func (j *journal) begin(sourcePath, destPath, tempPath string, move bool) (journalEntry, error) {
+	host, _ := os.Hostname()
+	e := journalEntry{
+		Op:     opBegin,
+		Source: absPath(sourcePath),
+		Dest:   absPath(destPath),
+		Temp:   absPath(tempPath),
+		Move:   move,
+		PID:    os.Getpid(),
+		Host:   host,
+	}
+	return e, j.append(e)
+}
+
+// done logs that the copy of e is finished, failed when err is not nil.
+func (j *journal) done(e journalEntry, err error) error {
+	e.Op = opDone
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return j.append(e)
+}
+
+func (j *journal) append(e journalEntry) error {
+	if j == nil {
+		return nil
+	}
+	e.Time = time.Now()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return f.Sync()
+}
+
+// unfinished returns the copies begun but never finished, in log order.
+func (j *journal) unfinished() ([]journalEntry, error) {
+	f, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var open []journalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // torn last line of a crashed write
+		}
+
+		switch e.Op {
+		case opBegin:
+			open = append(open, e)
+		case opDone:
+			for i, b := range open {
+				if b.Temp == e.Temp {
+					open = append(open[:i], open[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	return open, scanner.Err()
+}
+
+// repair finishes or rolls back the copies left unfinished by sessions
+// that no longer run and logs them as finished. Copies of other running
+// sessions sharing the journal are left alone. Returns a description of
+// each repaired copy and of each copy that could not be repaired.
+// The journal is compacted afterwards.
+//
+// A temporary file still present means the copy never reached its
+// destination: it is removed and the source is left as it was. Once the
+// copy was renamed into place it is complete, so a move whose source
+// remains is finished by removing the source.
+func (j *journal) repair() ([]string, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	open, err := j.unfinished()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var repaired []string
+	for _, e := range open {
+		if !e.orphaned() {
+			continue
+		}
+
+		result := errInterrupted
+		msg, err := e.repair()
+		if err != nil {
+			msg = fmt.Sprintf("could not repair copy of %s, left as it is: %v", e.Source, err)
+			result = fmt.Errorf("%w: %w", errUnrepaired, err)
+		}
+		if msg != "" {
+			repaired = append(repaired, msg)
+		}
+		if err := j.done(e, result); err != nil {
+			return repaired, err
+		}
+	}
+
+	if err := j.compact(); err != nil {
+		return repaired, fmt.Errorf("failed to compact journal: %w", err)
+	}
+	return repaired, nil
+}
+
+// compact rewrites the journal with only the copies still unfinished,
+// so it does not grow with every copy. A journal changed by another
+// session while it is rewritten is left as it is, as the lines logged
+// meanwhile would be lost; it is compacted on a later start.
+func (j *journal) compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	before, err := os.Stat(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	open, err := j.unfinished()
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := createTemp(j.path)
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+	defer tempFile.Close()
+
+	w := bufio.NewWriter(tempFile)
+	for _, e := range open {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		return err
+	}
+
+	after, err := os.Stat(j.path)
+	if err != nil || after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return err
+	}
+	if err := os.Rename(tempPath, j.path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(j.path))
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// orphaned reports whether the session that began e no longer runs.
+// Repairs run on start before this session begins any copy, so an entry
+// with our own PID was left by an earlier session that had it. Entries
+// without a PID predate sessions being logged.
+func (e journalEntry) orphaned() bool {
+	return e.PID == 0 || e.PID == os.Getpid() || !lock.Running(e.PID, e.Host)
+}
+
+func (e journalEntry) repair() (string, error) {
+	if _, err := os.Lstat(e.Temp); err == nil {
+		if err := os.Remove(e.Temp); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed partial copy of %s", e.Source), nil
+	}
+
+	if !e.Move {
+		return "", nil
+	}
+
+	source, srcErr := os.Lstat(e.Source)
+	dest, destErr := os.Lstat(e.Dest)
+	if srcErr != nil || destErr != nil {
+		return "", nil
+	}
+	if source.Size() != dest.Size() {
+		return "", fmt.Errorf("the file sizes do not match")
+	}
+
+	if err := os.Remove(e.Source); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("finished move of %s to %s", e.Source, e.Dest), nil
+}
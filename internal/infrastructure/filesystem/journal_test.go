@@ -0,0 +1,194 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournal_Repair(t *testing.T) {
+	setup := func(t *testing.T) (dir string, j *journal) {
+		dir = t.TempDir()
+		for _, name := range []string{"source.txt", ".target.txt.filer-1"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("content"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+		return dir, &journal{path: filepath.Join(dir, "state", "journal.jsonl")}
+	}
+
+	t.Run("should remove partial copy and keep source", func(t *testing.T) {
+		dir, j := setup(t)
+		if _, err := j.begin(filepath.Join(dir, "source.txt"), filepath.Join(dir, "target.txt"), filepath.Join(dir, ".target.txt.filer-1"), true); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		repaired, err := j.repair()
+
+		if err != nil || len(repaired) != 1 {
+			t.Fatalf("Expected one repaired copy, got %v (%v)", repaired, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".target.txt.filer-1")); !os.IsNotExist(err) {
+			t.Error("Partial copy should be removed")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "source.txt")); err != nil {
+			t.Error("Source should stay in place")
+		}
+		if open, err := j.unfinished(); err != nil || len(open) != 0 {
+			t.Errorf("Repaired copy should be logged as finished, got %v (%v)", open, err)
+		}
+	})
+
+	t.Run("should finish move renamed into place", func(t *testing.T) {
+		dir, j := setup(t)
+		if err := os.Rename(filepath.Join(dir, ".target.txt.filer-1"), filepath.Join(dir, "target.txt")); err != nil {
+			t.Fatalf("Failed to rename copy: %v", err)
+		}
+		if _, err := j.begin(filepath.Join(dir, "source.txt"), filepath.Join(dir, "target.txt"), filepath.Join(dir, ".target.txt.filer-1"), true); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		if _, err := j.repair(); err != nil {
+			t.Fatalf("Failed to repair: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "source.txt")); !os.IsNotExist(err) {
+			t.Error("Source of a finished copy should be removed")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "target.txt")); err != nil {
+			t.Error("Copy should stay in place")
+		}
+	})
+
+	t.Run("should leave finished copies alone", func(t *testing.T) {
+		dir, j := setup(t)
+		e, err := j.begin(filepath.Join(dir, "source.txt"), filepath.Join(dir, "target.txt"), filepath.Join(dir, ".target.txt.filer-1"), false)
+		if err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+		if err := j.done(e, nil); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		repaired, err := j.repair()
+
+		if err != nil || len(repaired) != 0 {
+			t.Errorf("Expected nothing to repair, got %v (%v)", repaired, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".target.txt.filer-1")); err != nil {
+			t.Error("Files of finished copies should not be touched")
+		}
+	})
+
+	t.Run("should leave copies of running sessions alone", func(t *testing.T) {
+		dir, j := setup(t)
+		host, err := os.Hostname()
+		if err != nil {
+			t.Skipf("Host name unknown: %v", err)
+		}
+		e := journalEntry{
+			Op:     opBegin,
+			Source: filepath.Join(dir, "source.txt"),
+			Dest:   filepath.Join(dir, "target.txt"),
+			Temp:   filepath.Join(dir, ".target.txt.filer-1"),
+			Move:   true,
+			PID:    os.Getppid(),
+			Host:   host,
+		}
+		if err := j.append(e); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		repaired, err := j.repair()
+
+		if err != nil || len(repaired) != 0 {
+			t.Errorf("Expected nothing to repair, got %v (%v)", repaired, err)
+		}
+		if _, err := os.Stat(e.Temp); err != nil {
+			t.Error("Copy in progress should not be touched")
+		}
+		if open, _ := j.unfinished(); len(open) != 1 {
+			t.Errorf("Copy in progress should stay open, got %v", open)
+		}
+	})
+
+	t.Run("should log copies that cannot be repaired as finished", func(t *testing.T) {
+		dir, j := setup(t)
+		if err := os.WriteFile(filepath.Join(dir, "target.txt"), []byte("part"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Remove(filepath.Join(dir, ".target.txt.filer-1")); err != nil {
+			t.Fatalf("Failed to remove copy: %v", err)
+		}
+		if _, err := j.begin(filepath.Join(dir, "source.txt"), filepath.Join(dir, "target.txt"), filepath.Join(dir, ".target.txt.filer-1"), true); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		repaired, err := j.repair()
+
+		if err != nil {
+			t.Fatalf("Expected a failed repair not to fail, got %v", err)
+		}
+		if len(repaired) != 1 || !strings.Contains(repaired[0], "could not repair") {
+			t.Errorf("Expected the failed repair to be reported, got %v", repaired)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "source.txt")); err != nil {
+			t.Error("Source should stay in place")
+		}
+		if open, err := j.unfinished(); err != nil || len(open) != 0 {
+			t.Errorf("Failed repair should be logged as finished, got %v (%v)", open, err)
+		}
+	})
+
+	t.Run("should compact the journal to copies still unfinished", func(t *testing.T) {
+		dir, j := setup(t)
+		host, err := os.Hostname()
+		if err != nil {
+			t.Skipf("Host name unknown: %v", err)
+		}
+		e, err := j.begin(filepath.Join(dir, "source.txt"), filepath.Join(dir, "target.txt"), filepath.Join(dir, ".target.txt.filer-1"), false)
+		if err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+		if err := j.done(e, nil); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+		running := journalEntry{
+			Op:     opBegin,
+			Source: filepath.Join(dir, "other.txt"),
+			Dest:   filepath.Join(dir, "target-other.txt"),
+			Temp:   filepath.Join(dir, ".target-other.txt.filer-2"),
+			PID:    os.Getppid(),
+			Host:   host,
+		}
+		if err := j.append(running); err != nil {
+			t.Fatalf("Failed to log copy: %v", err)
+		}
+
+		if _, err := j.repair(); err != nil {
+			t.Fatalf("Failed to repair: %v", err)
+		}
+
+		logged, err := os.ReadFile(j.path)
+		if err != nil {
+			t.Fatalf("Failed to read journal: %v", err)
+		}
+		if lines := strings.Split(strings.TrimSpace(string(logged)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "other.txt") {
+			t.Errorf("Expected only the copy in progress left, got %s", logged)
+		}
+		if open, _ := j.unfinished(); len(open) != 1 || open[0].Temp != running.Temp {
+			t.Errorf("Copy in progress should stay open, got %v", open)
+		}
+		if entries, _ := os.ReadDir(filepath.Dir(j.path)); len(entries) != 1 {
+			t.Errorf("Expected no temporary journal left, got %v", entries)
+		}
+	})
+
+	t.Run("should do nothing without a journal", func(t *testing.T) {
+		var j *journal
+		if repaired, err := j.repair(); err != nil || repaired != nil {
+			t.Errorf("Expected nothing to repair, got %v (%v)", repaired, err)
+		}
+	})
+}
@@ -1,17 +1,99 @@
 package filesystem
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rycln/filer/internal/domain"
+	"github.com/rycln/filer/internal/infrastructure/exif"
+	"github.com/rycln/filer/internal/infrastructure/lock"
 )
 
+// errNoTarget is returned by actions that need a target directory.
+var errNoTarget = errors.New("no target directory set")
+
 type Local struct {
-	source string
-	target string
+	source   string
+	target   string
+	template string
+
+	mu       sync.Mutex
+	kept     map[string]string // destination of files kept, copied or linked
+	warnings []string
+	journal  *journal
+	verify   bool
+	quota    int64            // bytes the session may move, 0 without a quota
+	claims   map[string]int64 // bytes of files counted against the quota
+	claimed  int64
+}
+
+// Option customizes a Local file system.
+type Option func(*Local)
+
+// WithTargetTemplate sorts kept files into directories expanded from
+// tmpl, e.g. "Archive/{year}/{month}". Replaces the target directory.
+func WithTargetTemplate(tmpl string) Option {
+	return func(l *Local) {
+		l.template = tmpl
+	}
+}
+
+// WithJournal logs copies between file systems to the file at path so
+// Repair can clean up after copies cut short by a crash.
+func WithJournal(path string) Option {
+	return func(l *Local) {
+		l.journal = &journal{path: path}
+	}
 }
 
-func NewLocal(source, target string) (*Local, error) {
+// WithVerify compares SHA-256 checksums of the source and the copy read
+// back from disk before a copied file is kept.
+func WithVerify() Option {
+	return func(l *Local) {
+		l.verify = true
+	}
+}
+
+// WithQuota limits the bytes kept, copied or renamed into the target
+// directory during the session.
+func WithQuota(bytes int64) Option {
+	return func(l *Local) {
+		l.quota = bytes
+	}
+}
+
+func NewLocal(source, target string, opts ...Option) (*Local, error) {
+	l := &Local{
+		source: source,
+		target: target,
+		kept:   make(map[string]string),
+		claims: make(map[string]int64),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.template != "" {
+		if _, err := domain.ExpandName(l.template, domain.NameVars{}); err != nil {
+			return nil, fmt.Errorf("invalid target template: %w", err)
+		}
+		return l, nil
+	}
+
 	if target != "" {
 		err := os.MkdirAll(target, 0755)
 		if err != nil {
@@ -19,26 +101,120 @@ func NewLocal(source, target string) (*Local, error) {
 		}
 	}
 
-	return &Local{
-		source: source,
-		target: target,
-	}, nil
+	return l, nil
 }
 
-func (l *Local) KeepFile(filename string) error {
-	if l.target == "" {
-		return nil
+// targetDir returns the directory filename is kept in, creating
+// template directories as needed. Empty when no target is set.
+func (l *Local) targetDir(filename string) (string, error) {
+	if l.template == "" {
+		return l.target, nil
+	}
+
+	path := l.source + "/" + filename
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
 	}
 
-	err := moveFileSafe(l.source+"/"+filename, l.target+"/"+filename)
+	dir, err := domain.ExpandName(l.template, domain.NameVars{
+		Name:    filename,
+		ModTime: info.ModTime(),
+		Date:    captureDate(path, info),
+		Parent:  l.parent(),
+	})
 	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// captureDate returns the EXIF capture date of an image.
+// Zero for other files.
+func captureDate(path string, info os.FileInfo) time.Time {
+	return imageTags(path, info).DateTimeOriginal
+}
+
+// imageTags reads EXIF tags of regular files.
+// Empty for other files and files without image metadata.
+func imageTags(path string, info os.FileInfo) exif.Tags {
+	if !info.Mode().IsRegular() {
+		return exif.Tags{}
+	}
+
+	tags, err := exif.ReadFile(path)
+	if err != nil {
+		return exif.Tags{}
+	}
+
+	return tags
+}
+
+// parent returns the name of the source directory.
+func (l *Local) parent() string {
+	dir, err := filepath.Abs(l.source)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dir)
+}
+
+func (l *Local) KeepFile(ctx context.Context, filename string) error {
+	dir, err := l.targetDir(filename)
+	if err != nil || dir == "" {
+		return err
+	}
+
+	if err := l.claim(filename); err != nil {
+		return err
+	}
+	err = l.moveFileSafe(ctx, l.source+"/"+filename, dir+"/"+filename)
+	if err != nil {
+		l.unclaim(filename)
+		return err
+	}
+
+	l.remember(filename, dir+"/"+filename)
+	return nil
+}
+
+func (l *Local) KeepFileAs(ctx context.Context, filename, newName string) error {
+	dir, err := l.targetDir(filename)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		dir = l.source
+	}
+	destPath := dir + "/" + newName
+
+	if dir == l.source && newName == filename {
+		return nil
+	}
+
+	if dir != l.source {
+		if err := l.claim(filename); err != nil {
+			return err
+		}
+	}
+	if err := l.moveFileNew(ctx, l.source+"/"+filename, destPath); err != nil {
+		l.unclaim(filename)
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("file already exists: %s: %w", destPath, err)
+		}
 		return err
 	}
 
+	l.remember(filename, destPath)
 	return nil
 }
 
-func moveFileSafe(sourcePath, destPath string) error {
+func (l *Local) moveFileSafe(ctx context.Context, sourcePath, destPath string) error {
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
 		return fmt.Errorf("file does not exist: %s", sourcePath)
 	}
@@ -48,37 +224,446 @@ func moveFileSafe(sourcePath, destPath string) error {
 		return nil
 	}
 
-	return copyAndRemove(sourcePath, destPath)
+	return l.copyAndRemove(ctx, sourcePath, destPath)
+}
+
+// moveFileNew moves sourcePath to destPath like moveFileSafe, but fails
+// with fs.ErrExist instead of replacing a file at destPath. The name is
+// claimed atomically, so of two moves to the same name only one wins.
+func (l *Local) moveFileNew(ctx context.Context, sourcePath, destPath string) error {
+	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %s", sourcePath)
+	}
+
+	err := renameNoReplace(sourcePath, destPath)
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		return err
+	}
+
+	return l.copyFile(ctx, sourcePath, destPath, true, renameNoReplace)
 }
 
-func copyAndRemove(sourcePath, destPath string) error {
+func (l *Local) copyAndRemove(ctx context.Context, sourcePath, destPath string) error {
+	return l.copyFile(ctx, sourcePath, destPath, true, os.Rename)
+}
+
+// copyFile copies sourcePath to destPath, reporting progress to ctx.
+// The data goes to a temporary file in the destination directory that
+// is synced, checked and moved into place with place, so destPath never
+// holds a partial copy. A move removes the source once the rename is on disk.
+// Copies that would not fit into the free space are refused up front.
+// The journal records the copy until it is finished, along with how it
+// was copied and its checksum when copies are verified. Metadata of the
+// source that cannot be kept is a warning rather than an error.
+func (l *Local) copyFile(ctx context.Context, sourcePath, destPath string, move bool, place func(oldpath, newpath string) error) error {
 	sourceFile, err := os.Open(sourcePath)
 	if err != nil {
 		return err
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(destPath)
+	sourceInfo, err := sourceFile.Stat()
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
+	dir := filepath.Dir(destPath)
+	if err := checkFree(dir, sourceInfo.Size()); err != nil {
+		return err
+	}
+	tempFile, err := createTemp(destPath)
 	if err != nil {
-		os.Remove(destPath)
 		return err
 	}
+	tempPath := tempFile.Name()
+	defer tempFile.Close()
+
+	entry, err := l.journal.begin(sourcePath, destPath, tempPath, move)
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := l.writeCopy(ctx, sourceFile, tempFile, &entry); err != nil {
+		l.journal.done(entry, err)
+		os.Remove(tempPath)
+		return err
+	}
+	for _, err := range preserveMetadata(sourceFile, sourceInfo, tempFile) {
+		l.warn(destPath, err)
+	}
+
+	if err := place(tempPath, destPath); err != nil {
+		l.journal.done(entry, err)
+		os.Remove(tempPath)
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		l.journal.done(entry, err)
+		return err
+	}
+
+	if move {
+		if err := os.Remove(sourcePath); err != nil {
+			l.journal.done(entry, err)
+			return err
+		}
+	}
+
+	return l.journal.done(entry, nil)
+}
 
-	sourceInfo, _ := sourceFile.Stat()
-	destInfo, _ := destFile.Stat()
+// checkFree fails with domain.ErrNoSpace when size bytes do not fit
+// into the free space of dir.
+func checkFree(dir string, size int64) error {
+	free, err := freeSpace(dir)
+	if err != nil || free < 0 || size <= free {
+		return err
+	}
+	return fmt.Errorf("%w: needs %s, %s free", domain.ErrNoSpace, domain.FormatSize(size), domain.FormatSize(free))
+}
+
+// linkRename renames oldpath to newpath by hard linking and removing
+// oldpath. Linking fails when newpath exists, so it is never replaced.
+func linkRename(oldpath, newpath string) error {
+	if err := os.Link(oldpath, newpath); err != nil {
+		return err
+	}
+	return os.Remove(oldpath)
+}
+
+// createTemp creates a hidden temporary file next to destPath.
+// Unlike os.CreateTemp it leaves the permissions to the umask.
+func createTemp(destPath string) (*os.File, error) {
+	for {
+		name := fmt.Sprintf("%s/.%s.filer-%d", filepath.Dir(destPath), filepath.Base(destPath), rand.Uint32())
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if !errors.Is(err, os.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+// Copy strategies logged in the journal, fastest first.
+const (
+	strategyReflink  = "reflink"
+	strategyRange    = "copy_file_range"
+	strategyBuffered = "buffered"
+)
+
+// writeCopy copies source to dest, preferring the kernel fast paths of
+// fastCopy over a buffered copy, and syncs dest to disk. Fails when the
+// sizes of both files differ afterwards. With verification dest is read
+// back from disk and its SHA-256 compared to the data of source; outside
+// Linux the read may be served from the page cache. The strategy and
+// checksum are stored in entry.
+func (l *Local) writeCopy(ctx context.Context, source, dest *os.File, entry *journalEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	report := domain.ProgressFrom(ctx)
+	hash := sha256.New()
+
+	strategy, err := fastCopy(ctx, source, dest, report)
+	if err != nil {
+		return err
+	}
+	if strategy == "" {
+		strategy = strategyBuffered
+		var w io.Writer = dest
+		if l.verify {
+			w = io.MultiWriter(dest, hash)
+		}
+		if _, err := io.Copy(w, &progressReader{ctx: ctx, r: source, report: report}); err != nil {
+			return err
+		}
+	} else if l.verify {
+		// The data never passed through here, so read the source too.
+		if _, err := io.Copy(hash, io.NewSectionReader(source, 0, math.MaxInt64)); err != nil {
+			return err
+		}
+	}
+	entry.Strategy = strategy
+
+	if err := dest.Sync(); err != nil {
+		return err
+	}
+
+	sourceInfo, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	destInfo, err := dest.Stat()
+	if err != nil {
+		return err
+	}
 
 	if sourceInfo.Size() != destInfo.Size() {
-		os.Remove(destPath)
 		return fmt.Errorf("the file sizes do not match")
 	}
 
-	return os.Remove(sourcePath)
+	if !l.verify {
+		return nil
+	}
+
+	// Read back from disk rather than the pages just written.
+	if err := dropCache(dest); err != nil {
+		return err
+	}
+	want := hex.EncodeToString(hash.Sum(nil))
+	got, err := checksum(dest)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("checksum mismatch: copied %s, read back %s", want, got)
+	}
+
+	entry.SHA256 = want
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of f from its start.
+func checksum(f *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(f, 0, math.MaxInt64)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// progressReader reports bytes read so far and fails once ctx is done.
+type progressReader struct {
+	ctx    context.Context
+	r      io.Reader
+	n      int64
+	report func(int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	r.report(r.n)
+	return n, err
+}
+
+func (l *Local) CopyFile(ctx context.Context, filename string) error {
+	dir, err := l.requireTarget(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := l.claim(filename); err != nil {
+		return err
+	}
+	destPath := dir + "/" + filename
+	if err := l.copyFile(ctx, l.source+"/"+filename, destPath, false, os.Rename); err != nil {
+		l.unclaim(filename)
+		return err
+	}
+
+	l.remember(filename, destPath)
+	return nil
+}
+
+func (l *Local) SymlinkFile(filename string) error {
+	dir, err := l.requireTarget(filename)
+	if err != nil {
+		return err
+	}
+
+	sourcePath, err := filepath.Abs(l.source + "/" + filename)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(sourcePath); err != nil {
+		return err
+	}
+
+	destPath := dir + "/" + filename
+	if err := os.Symlink(sourcePath, destPath); err != nil {
+		return err
+	}
+
+	l.remember(filename, destPath)
+	return nil
+}
+
+func (l *Local) HardlinkFile(filename string) error {
+	dir, err := l.requireTarget(filename)
+	if err != nil {
+		return err
+	}
+
+	destPath := dir + "/" + filename
+	if err := os.Link(l.source+"/"+filename, destPath); err != nil {
+		return err
+	}
+
+	l.remember(filename, destPath)
+	return nil
+}
+
+func (l *Local) hasTarget() bool {
+	return l.target != "" || l.template != ""
+}
+
+// requireTarget is targetDir for actions that need a target directory.
+func (l *Local) requireTarget(filename string) (string, error) {
+	if !l.hasTarget() {
+		return "", errNoTarget
+	}
+	return l.targetDir(filename)
+}
+
+// claim counts filename against the quota. Fails with domain.ErrQuota
+// when it does not fit into what is left.
+func (l *Local) claim(filename string) error {
+	if l.quota <= 0 {
+		return nil
+	}
+
+	size, err := l.FileSize(filename)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if left := l.quota - l.claimed; size > left {
+		return fmt.Errorf("%w: needs %s, %s left", domain.ErrQuota, domain.FormatSize(size), domain.FormatSize(left))
+	}
+	l.claims[filename] = size
+	l.claimed += size
+	return nil
+}
+
+// unclaim returns the bytes claimed for filename to the quota.
+func (l *Local) unclaim(filename string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.claimed -= l.claims[filename]
+	delete(l.claims, filename)
+}
+
+// TargetSpace returns the free space on the target file system and what
+// is left of the quota. Unknown without a target directory.
+func (l *Local) TargetSpace() (domain.TargetSpace, error) {
+	space := domain.TargetSpace{Free: -1, Quota: -1}
+
+	if l.quota > 0 {
+		l.mu.Lock()
+		space.Quota = l.quota - l.claimed
+		l.mu.Unlock()
+	}
+
+	if !l.hasTarget() {
+		return space, nil
+	}
+
+	free, err := freeSpace(existingDir(l.targetRoot()))
+	if err != nil {
+		return space, err
+	}
+	space.Free = free
+	return space, nil
+}
+
+// targetRoot returns the target directory, or the part of the target
+// template before its first placeholder.
+func (l *Local) targetRoot() string {
+	if l.template == "" {
+		return l.target
+	}
+	root, _, _ := strings.Cut(l.template, "{")
+	return root
+}
+
+// existingDir returns path or its closest existing parent.
+func existingDir(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// remember records where filename was kept so Restore can undo it.
+func (l *Local) remember(filename, destPath string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.kept[filename] = destPath
+}
+
+// warn records a problem with path that did not fail the operation.
+func (l *Local) warn(path string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf("%s: %v", path, err))
+}
+
+// Warnings returns the problems that did not fail an operation, such
+// as metadata that could not be kept on a copy.
+func (l *Local) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.warnings)
+}
+
+// Created reports whether filename in the source directory was written
+// by keeping another file, e.g. renaming it in place.
+func (l *Local) Created(filename string) bool {
+	path := l.source + "/" + filename
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, destPath := range l.kept {
+		if destPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore undoes the last keep, copy or link of filename.
+// Moved files go back to the source; copies and links are removed.
+// Runs to the end even when the operation it undoes was cancelled.
+func (l *Local) Restore(filename string) error {
+	l.mu.Lock()
+	destPath, ok := l.kept[filename]
+	delete(l.kept, filename)
+	l.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	l.unclaim(filename)
+
+	sourcePath := l.source + "/" + filename
+	if _, err := os.Lstat(sourcePath); err == nil {
+		return os.Remove(destPath)
+	}
+
+	return l.moveFileSafe(context.Background(), destPath, sourcePath)
+}
+
+// Repair finishes or rolls back copies between file systems left
+// unfinished by an earlier session and describes what was repaired.
+func (l *Local) Repair() ([]string, error) {
+	return l.journal.repair()
 }
 
 func (l *Local) DeleteFile(filename string) error {
@@ -90,6 +675,56 @@ func (l *Local) DeleteFile(filename string) error {
 	return nil
 }
 
+func (l *Local) FileSize(filename string) (int64, error) {
+	info, err := os.Lstat(l.source + "/" + filename)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func (l *Local) Metadata(filename string) (domain.FileMeta, error) {
+	path := l.source + "/" + filename
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return domain.FileMeta{}, err
+	}
+
+	meta := domain.FileMeta{
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		Symlink: info.Mode()&os.ModeSymlink != 0,
+	}
+
+	if meta.Symlink {
+		meta.LinkTarget, _ = os.Readlink(path)
+	}
+
+	meta.Parent = l.parent()
+	fillImageMetadata(path, info, &meta)
+
+	fillSysMetadata(path, &meta)
+
+	return meta, nil
+}
+
+// fillImageMetadata adds EXIF fields of images to meta.
+func fillImageMetadata(path string, info os.FileInfo, meta *domain.FileMeta) {
+	tags := imageTags(path, info)
+
+	meta.Taken = tags.DateTimeOriginal
+	meta.Camera = tags.Camera()
+	meta.Rotated = tags.Rotated()
+	meta.GPS = tags.GPS
+	meta.Width, meta.Height = tags.Width, tags.Height
+	if meta.Rotated {
+		meta.Width, meta.Height = tags.Height, tags.Width
+	}
+}
+
 func (l *Local) GetFilenames() ([]string, error) {
 	entries, err := os.ReadDir(l.source)
 	if err != nil {
@@ -99,7 +734,7 @@ func (l *Local) GetFilenames() ([]string, error) {
 	var filenames []string
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || entry.Name() == lock.Name {
 			continue
 		}
 		filenames = append(filenames, entry.Name())
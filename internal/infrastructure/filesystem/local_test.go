@@ -1,9 +1,17 @@
 package filesystem
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/rycln/filer/internal/domain"
+	"github.com/rycln/filer/internal/infrastructure/lock"
 )
 
 func TestNewLocal(t *testing.T) {
@@ -117,7 +125,7 @@ func TestLocal_KeepFile(t *testing.T) {
 			t.Fatalf("Failed to create local filesystem: %v", err)
 		}
 
-		err = local.KeepFile("somefile.txt")
+		err = local.KeepFile(context.Background(), "somefile.txt")
 		if err != nil {
 			t.Errorf("Expected no error with empty target, got %v", err)
 		}
@@ -149,7 +157,7 @@ func TestLocal_KeepFile(t *testing.T) {
 			t.Fatalf("Failed to create local filesystem: %v", err)
 		}
 
-		err = local.KeepFile(testFile)
+		err = local.KeepFile(context.Background(), testFile)
 		if err != nil {
 			t.Errorf("Failed to keep file: %v", err)
 		}
@@ -184,13 +192,470 @@ func TestLocal_KeepFile(t *testing.T) {
 			t.Fatalf("Failed to create local filesystem: %v", err)
 		}
 
-		err = local.KeepFile("nonexistent.txt")
+		err = local.KeepFile(context.Background(), "nonexistent.txt")
 		if err == nil {
 			t.Error("Expected error for non-existent file")
 		}
 	})
 }
 
+func TestLocal_KeepFileAs(t *testing.T) {
+	setup := func(t *testing.T, target string) (*Local, string) {
+		tempSource := t.TempDir()
+		for _, name := range []string{"IMG_1.jpg", "taken.jpg"} {
+			if err := os.WriteFile(filepath.Join(tempSource, name), []byte(name), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		local, err := NewLocal(tempSource, target)
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+		return local, tempSource
+	}
+
+	t.Run("should move file to target under new name", func(t *testing.T) {
+		tempTarget := t.TempDir()
+		local, tempSource := setup(t, tempTarget)
+
+		if err := local.KeepFileAs(context.Background(), "IMG_1.jpg", "beach.jpg"); err != nil {
+			t.Fatalf("Failed to keep file: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(tempTarget, "beach.jpg"))
+		if err != nil || string(content) != "IMG_1.jpg" {
+			t.Errorf("Expected renamed file in target, got %q (%v)", content, err)
+		}
+		if _, err := os.Stat(filepath.Join(tempSource, "IMG_1.jpg")); !os.IsNotExist(err) {
+			t.Error("File was not removed from source directory")
+		}
+	})
+
+	t.Run("should rename in place without target", func(t *testing.T) {
+		local, tempSource := setup(t, "")
+
+		if err := local.KeepFileAs(context.Background(), "IMG_1.jpg", "beach.jpg"); err != nil {
+			t.Fatalf("Failed to rename file: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempSource, "beach.jpg")); err != nil {
+			t.Error("Expected renamed file in source directory")
+		}
+	})
+
+	t.Run("should refuse to overwrite existing file", func(t *testing.T) {
+		local, tempSource := setup(t, "")
+
+		if err := local.KeepFileAs(context.Background(), "IMG_1.jpg", "taken.jpg"); err == nil {
+			t.Error("Expected error for name collision")
+		}
+
+		content, _ := os.ReadFile(filepath.Join(tempSource, "taken.jpg"))
+		if string(content) != "taken.jpg" {
+			t.Error("Existing file should be left untouched")
+		}
+	})
+
+	t.Run("should let only one of two renames to the same name win", func(t *testing.T) {
+		tempTarget := t.TempDir()
+		local, tempSource := setup(t, tempTarget)
+
+		errs := make(chan error, 2)
+		for _, name := range []string{"IMG_1.jpg", "taken.jpg"} {
+			go func() {
+				errs <- local.KeepFileAs(context.Background(), name, "2021-03-09.jpg")
+			}()
+		}
+
+		var failed int
+		for range 2 {
+			if err := <-errs; err != nil {
+				if !errors.Is(err, fs.ErrExist) {
+					t.Errorf("Expected fs.ErrExist, got %v", err)
+				}
+				failed++
+			}
+		}
+		if failed != 1 {
+			t.Fatalf("Expected exactly one rename to fail, got %d", failed)
+		}
+
+		content, err := os.ReadFile(filepath.Join(tempTarget, "2021-03-09.jpg"))
+		if err != nil {
+			t.Fatalf("Expected renamed file in target: %v", err)
+		}
+		left := "IMG_1.jpg"
+		if string(content) == left {
+			left = "taken.jpg"
+		}
+		if _, err := os.Stat(filepath.Join(tempSource, left)); err != nil {
+			t.Errorf("The losing file should stay in the source: %v", err)
+		}
+	})
+
+	t.Run("should not replace an existing file when copying across file systems", func(t *testing.T) {
+		tempTarget := t.TempDir()
+		local, tempSource := setup(t, tempTarget)
+		destPath := filepath.Join(tempTarget, "beach.jpg")
+		if err := os.WriteFile(destPath, []byte("beach"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		err := local.copyFile(context.Background(), filepath.Join(tempSource, "IMG_1.jpg"), destPath, true, renameNoReplace)
+
+		if !errors.Is(err, fs.ErrExist) {
+			t.Errorf("Expected fs.ErrExist, got %v", err)
+		}
+		if content, _ := os.ReadFile(destPath); string(content) != "beach" {
+			t.Error("Existing file should be left untouched")
+		}
+		if _, err := os.Stat(filepath.Join(tempSource, "IMG_1.jpg")); err != nil {
+			t.Errorf("Source should stay in place: %v", err)
+		}
+		if entries, _ := os.ReadDir(tempTarget); len(entries) != 1 {
+			t.Errorf("Expected the temporary copy to be removed, got %v", entries)
+		}
+	})
+
+	t.Run("should accept unchanged name without target", func(t *testing.T) {
+		local, _ := setup(t, "")
+
+		if err := local.KeepFileAs(context.Background(), "IMG_1.jpg", "IMG_1.jpg"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestLocal_CopyAndLinkFile(t *testing.T) {
+	setup := func(t *testing.T) (*Local, string, string) {
+		tempSource := t.TempDir()
+		tempTarget := t.TempDir()
+
+		err := os.WriteFile(filepath.Join(tempSource, "testfile.txt"), []byte("test content"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		local, err := NewLocal(tempSource, tempTarget)
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+		return local, tempSource, tempTarget
+	}
+
+	t.Run("should copy file and keep original", func(t *testing.T) {
+		local, tempSource, tempTarget := setup(t)
+
+		if err := local.CopyFile(context.Background(), "testfile.txt"); err != nil {
+			t.Fatalf("Failed to copy file: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(tempTarget, "testfile.txt"))
+		if err != nil || string(content) != "test content" {
+			t.Errorf("Expected copied content, got %q (%v)", content, err)
+		}
+		if _, err := os.Stat(filepath.Join(tempSource, "testfile.txt")); err != nil {
+			t.Error("Original file should stay in source")
+		}
+	})
+
+	t.Run("should symlink target to original", func(t *testing.T) {
+		local, tempSource, tempTarget := setup(t)
+
+		if err := local.SymlinkFile("testfile.txt"); err != nil {
+			t.Fatalf("Failed to symlink file: %v", err)
+		}
+
+		link, err := os.Readlink(filepath.Join(tempTarget, "testfile.txt"))
+		if err != nil {
+			t.Fatalf("Expected symlink in target: %v", err)
+		}
+		if !filepath.IsAbs(link) || link != filepath.Join(tempSource, "testfile.txt") {
+			t.Errorf("Expected absolute link to original, got %s", link)
+		}
+	})
+
+	t.Run("should hard link target to original", func(t *testing.T) {
+		local, tempSource, tempTarget := setup(t)
+
+		if err := local.HardlinkFile("testfile.txt"); err != nil {
+			t.Fatalf("Failed to hard link file: %v", err)
+		}
+
+		original, err := os.Stat(filepath.Join(tempSource, "testfile.txt"))
+		if err != nil {
+			t.Fatalf("Failed to stat original: %v", err)
+		}
+		linked, err := os.Stat(filepath.Join(tempTarget, "testfile.txt"))
+		if err != nil {
+			t.Fatalf("Failed to stat link: %v", err)
+		}
+		if !os.SameFile(original, linked) {
+			t.Error("Expected target to be the same file as the original")
+		}
+	})
+
+	t.Run("should return error for missing source", func(t *testing.T) {
+		local, _, _ := setup(t)
+
+		for name, fn := range map[string]func(string) error{
+			"copy":     func(f string) error { return local.CopyFile(context.Background(), f) },
+			"symlink":  local.SymlinkFile,
+			"hardlink": local.HardlinkFile,
+		} {
+			if err := fn("nonexistent.txt"); err == nil {
+				t.Errorf("Expected %s error for non-existent file", name)
+			}
+		}
+	})
+
+	t.Run("should return error without target", func(t *testing.T) {
+		local, err := NewLocal(t.TempDir(), "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		for name, fn := range map[string]func(string) error{
+			"copy":     func(f string) error { return local.CopyFile(context.Background(), f) },
+			"symlink":  local.SymlinkFile,
+			"hardlink": local.HardlinkFile,
+		} {
+			if err := fn("testfile.txt"); !errors.Is(err, errNoTarget) {
+				t.Errorf("Expected %s to fail without target, got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestLocal_Restore(t *testing.T) {
+	setup := func(t *testing.T) (*Local, string, string) {
+		tempSource := t.TempDir()
+		tempTarget := t.TempDir()
+
+		err := os.WriteFile(filepath.Join(tempSource, "testfile.txt"), []byte("test content"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		local, err := NewLocal(tempSource, tempTarget)
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+		return local, tempSource, tempTarget
+	}
+
+	t.Run("should move kept file back to source", func(t *testing.T) {
+		local, tempSource, tempTarget := setup(t)
+
+		if err := local.KeepFileAs(context.Background(), "testfile.txt", "renamed.txt"); err != nil {
+			t.Fatalf("Failed to keep file: %v", err)
+		}
+		if err := local.Restore("testfile.txt"); err != nil {
+			t.Fatalf("Failed to restore file: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(tempSource, "testfile.txt")); err != nil {
+			t.Error("Expected file back in source directory")
+		}
+		if _, err := os.Stat(filepath.Join(tempTarget, "renamed.txt")); !os.IsNotExist(err) {
+			t.Error("Expected file removed from target directory")
+		}
+	})
+
+	t.Run("should remove copies and links", func(t *testing.T) {
+		local, tempSource, tempTarget := setup(t)
+
+		for name, fn := range map[string]func(string) error{
+			"copy":     func(f string) error { return local.CopyFile(context.Background(), f) },
+			"symlink":  local.SymlinkFile,
+			"hardlink": local.HardlinkFile,
+		} {
+			if err := fn("testfile.txt"); err != nil {
+				t.Fatalf("Failed to %s file: %v", name, err)
+			}
+			if err := local.Restore("testfile.txt"); err != nil {
+				t.Fatalf("Failed to restore %s: %v", name, err)
+			}
+			if _, err := os.Lstat(filepath.Join(tempTarget, "testfile.txt")); !os.IsNotExist(err) {
+				t.Errorf("Expected %s removed from target directory", name)
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(tempSource, "testfile.txt")); err != nil {
+			t.Error("Original file should stay in source")
+		}
+	})
+
+	t.Run("should report files created in the source", func(t *testing.T) {
+		local, err := NewLocal(t.TempDir(), "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(local.source, "scan.pdf"), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		if err := local.KeepFileAs(context.Background(), "scan.pdf", "invoice.pdf"); err != nil {
+			t.Fatalf("Failed to rename file: %v", err)
+		}
+
+		if !local.Created("invoice.pdf") || local.Created("scan.pdf") {
+			t.Error("Expected only the renamed file to be reported")
+		}
+	})
+
+	t.Run("should do nothing for files not kept", func(t *testing.T) {
+		local, _, _ := setup(t)
+
+		if err := local.Restore("testfile.txt"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestLocal_TargetTemplate(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		tempSource := t.TempDir()
+		path := filepath.Join(tempSource, "IMG_1.jpg")
+		if err := os.WriteFile(path, []byte("no exif"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		mtime := time.Date(2021, 3, 9, 12, 0, 0, 0, time.Local)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+		return tempSource, t.TempDir()
+	}
+
+	t.Run("should move file into dated directories", func(t *testing.T) {
+		tempSource, archive := setup(t)
+
+		local, err := NewLocal(tempSource, "", WithTargetTemplate(archive+"/{year}/{month}"))
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		if err := local.KeepFile(context.Background(), "IMG_1.jpg"); err != nil {
+			t.Fatalf("Failed to keep file: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(archive, "2021", "03", "IMG_1.jpg")); err != nil {
+			t.Errorf("Expected file in dated directory: %v", err)
+		}
+	})
+
+	t.Run("should use template for copies and renames", func(t *testing.T) {
+		tempSource, archive := setup(t)
+
+		local, err := NewLocal(tempSource, "", WithTargetTemplate(archive+"/{date:2006-01}"))
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		if err := local.CopyFile(context.Background(), "IMG_1.jpg"); err != nil {
+			t.Fatalf("Failed to copy file: %v", err)
+		}
+		if err := local.KeepFileAs(context.Background(), "IMG_1.jpg", "beach.jpg"); err != nil {
+			t.Fatalf("Failed to rename file: %v", err)
+		}
+
+		for _, name := range []string{"IMG_1.jpg", "beach.jpg"} {
+			if _, err := os.Stat(filepath.Join(archive, "2021-03", name)); err != nil {
+				t.Errorf("Expected %s in dated directory: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("should return error for invalid template", func(t *testing.T) {
+		if _, err := NewLocal(t.TempDir(), "", WithTargetTemplate("{week}")); err == nil {
+			t.Error("Expected error for unknown placeholder")
+		}
+	})
+
+	t.Run("should return error for missing source", func(t *testing.T) {
+		tempSource, archive := setup(t)
+
+		local, err := NewLocal(tempSource, "", WithTargetTemplate(archive+"/{year}"))
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		if err := local.KeepFile(context.Background(), "nonexistent.jpg"); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+}
+
+func TestLocal_Quota(t *testing.T) {
+	setup := func(t *testing.T) *Local {
+		tempSource := t.TempDir()
+		for _, name := range []string{"file1.txt", "file2.txt"} {
+			if err := os.WriteFile(filepath.Join(tempSource, name), []byte("123456"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		local, err := NewLocal(tempSource, t.TempDir(), WithQuota(10))
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+		return local
+	}
+
+	t.Run("should refuse files over the quota", func(t *testing.T) {
+		local := setup(t)
+
+		if err := local.KeepFile(context.Background(), "file1.txt"); err != nil {
+			t.Fatalf("Failed to keep file: %v", err)
+		}
+		err := local.CopyFile(context.Background(), "file2.txt")
+
+		if !errors.Is(err, domain.ErrQuota) {
+			t.Errorf("Expected domain.ErrQuota, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(local.target, "file2.txt")); !os.IsNotExist(err) {
+			t.Error("File over the quota should not be copied")
+		}
+	})
+
+	t.Run("should report quota left and return it on restore", func(t *testing.T) {
+		local := setup(t)
+
+		if err := local.KeepFile(context.Background(), "file1.txt"); err != nil {
+			t.Fatalf("Failed to keep file: %v", err)
+		}
+		space, err := local.TargetSpace()
+		if err != nil {
+			t.Fatalf("Failed to read target space: %v", err)
+		}
+		if space.Quota != 4 {
+			t.Errorf("Expected 4 bytes of quota left, got %d", space.Quota)
+		}
+
+		if err := local.Restore("file1.txt"); err != nil {
+			t.Fatalf("Failed to restore file: %v", err)
+		}
+		if space, _ := local.TargetSpace(); space.Quota != 10 {
+			t.Errorf("Expected the full quota back, got %d", space.Quota)
+		}
+	})
+
+	t.Run("should refuse copies larger than the free space", func(t *testing.T) {
+		dir := t.TempDir()
+		if free, _ := freeSpace(dir); free < 0 {
+			t.Skip("free space is unknown on this platform")
+		}
+
+		if err := checkFree(dir, 1<<62); !errors.Is(err, domain.ErrNoSpace) {
+			t.Errorf("Expected domain.ErrNoSpace, got %v", err)
+		}
+		if err := checkFree(dir, 1); err != nil {
+			t.Errorf("Expected small file to fit, got %v", err)
+		}
+	})
+}
+
 func TestLocal_DeleteFile(t *testing.T) {
 	t.Run("should delete existing file", func(t *testing.T) {
 		tempDir, err := os.MkdirTemp("", "test_source")
@@ -242,6 +707,165 @@ func TestLocal_DeleteFile(t *testing.T) {
 	})
 }
 
+func TestLocal_FileSize(t *testing.T) {
+	t.Run("should return size of existing file", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_source")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		err = os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("test content"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		size, err := local.FileSize("file.txt")
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if size != int64(len("test content")) {
+			t.Errorf("Expected size %d, got %d", len("test content"), size)
+		}
+	})
+
+	t.Run("should return error for non-existent file", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_source")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		_, err = local.FileSize("nonexistent.txt")
+		if err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+}
+
+func TestLocal_Metadata(t *testing.T) {
+	t.Run("should return metadata of regular file", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_source")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		err = os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("test content"), 0640)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		meta, err := local.Metadata("file.txt")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if meta.Size != int64(len("test content")) {
+			t.Errorf("Expected size %d, got %d", len("test content"), meta.Size)
+		}
+		if meta.Mode.Perm() != 0640 {
+			t.Errorf("Expected mode 0640, got %v", meta.Mode.Perm())
+		}
+		if meta.ModTime.IsZero() {
+			t.Error("Expected modification time")
+		}
+		if meta.Symlink {
+			t.Error("Regular file should not be reported as symlink")
+		}
+		if meta.Parent != filepath.Base(tempDir) {
+			t.Errorf("Expected parent %s, got %s", filepath.Base(tempDir), meta.Parent)
+		}
+		if meta.IsImage() {
+			t.Error("Text file should have no image metadata")
+		}
+	})
+
+	t.Run("should read image dimensions", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		// JPEG with a single 640x480 frame header.
+		jpeg := []byte{0xFF, 0xD8, 0xFF, 0xC0, 0x00, 0x08, 0x08, 0x01, 0xE0, 0x02, 0x80, 0x00, 0xFF, 0xD9}
+		if err := os.WriteFile(filepath.Join(tempDir, "photo.jpg"), jpeg, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		meta, err := local.Metadata("photo.jpg")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if meta.Width != 640 || meta.Height != 480 {
+			t.Errorf("Expected 640x480, got %dx%d", meta.Width, meta.Height)
+		}
+	})
+
+	t.Run("should report symlink and its target", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_source")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		err = os.Symlink("/nonexistent/target", filepath.Join(tempDir, "link"))
+		if err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		meta, err := local.Metadata("link")
+		if err != nil {
+			t.Fatalf("Expected no error for dangling symlink, got %v", err)
+		}
+		if !meta.Symlink {
+			t.Error("Expected symlink to be reported")
+		}
+		if meta.LinkTarget != "/nonexistent/target" {
+			t.Errorf("Expected link target /nonexistent/target, got %s", meta.LinkTarget)
+		}
+	})
+
+	t.Run("should return error for non-existent file", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_source")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		_, err = local.Metadata("nonexistent.txt")
+		if err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+}
+
 func TestLocal_GetFilenames(t *testing.T) {
 	t.Run("should return empty list for empty directory", func(t *testing.T) {
 		tempDir, err := os.MkdirTemp("", "test_source")
@@ -264,6 +888,28 @@ func TestLocal_GetFilenames(t *testing.T) {
 		}
 	})
 
+	t.Run("should skip the lock file", func(t *testing.T) {
+		tempDir := t.TempDir()
+		for _, name := range []string{"file.txt", lock.Name} {
+			if err := os.WriteFile(filepath.Join(tempDir, name), nil, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		local, err := NewLocal(tempDir, "")
+		if err != nil {
+			t.Fatalf("Failed to create local filesystem: %v", err)
+		}
+
+		filenames, err := local.GetFilenames()
+		if err != nil {
+			t.Fatalf("Failed to get filenames: %v", err)
+		}
+		if len(filenames) != 1 || filenames[0] != "file.txt" {
+			t.Errorf("Expected [file.txt], got %v", filenames)
+		}
+	})
+
 	t.Run("should return only files, not directories", func(t *testing.T) {
 		tempDir, err := os.MkdirTemp("", "test_source")
 		if err != nil {
@@ -331,8 +977,10 @@ func TestLocal_GetFilenames(t *testing.T) {
 }
 
 func Test_moveFileSafe(t *testing.T) {
+	local := &Local{}
+
 	t.Run("should return error when source file doesn't exist", func(t *testing.T) {
-		err := moveFileSafe("/nonexistent/source.txt", "/some/target.txt")
+		err := local.moveFileSafe(context.Background(), "/nonexistent/source.txt", "/some/target.txt")
 		if err == nil {
 			t.Error("Expected error for non-existent source file")
 		}
@@ -353,7 +1001,7 @@ func Test_moveFileSafe(t *testing.T) {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		err = moveFileSafe(sourcePath, targetPath)
+		err = local.moveFileSafe(context.Background(), sourcePath, targetPath)
 		if err != nil {
 			t.Errorf("Failed to move file: %v", err)
 		}
@@ -369,6 +1017,8 @@ func Test_moveFileSafe(t *testing.T) {
 }
 
 func Test_copyAndRemove(t *testing.T) {
+	local := &Local{}
+
 	t.Run("should copy file and remove original", func(t *testing.T) {
 		tempDir, err := os.MkdirTemp("", "test_copy")
 		if err != nil {
@@ -385,7 +1035,7 @@ func Test_copyAndRemove(t *testing.T) {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		err = copyAndRemove(sourcePath, targetPath)
+		err = local.copyAndRemove(context.Background(), sourcePath, targetPath)
 		if err != nil {
 			t.Errorf("Failed to copy and remove file: %v", err)
 		}
@@ -410,8 +1060,142 @@ func Test_copyAndRemove(t *testing.T) {
 		}
 	})
 
+	t.Run("should report copied bytes", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.txt")
+		targetPath := filepath.Join(tempDir, "target.txt")
+
+		content := []byte("test content for progress")
+		if err := os.WriteFile(sourcePath, content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		var copied int64
+		ctx := domain.WithProgress(context.Background(), func(n int64) { copied = n })
+		if err := local.copyAndRemove(ctx, sourcePath, targetPath); err != nil {
+			t.Fatalf("Failed to copy and remove file: %v", err)
+		}
+
+		if copied != int64(len(content)) {
+			t.Errorf("Expected %d bytes reported, got %d", len(content), copied)
+		}
+	})
+
+	t.Run("should keep source and remove partial copy when cancelled", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.txt")
+		targetPath := filepath.Join(tempDir, "target.txt")
+
+		if err := os.WriteFile(sourcePath, []byte("test content"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := local.copyAndRemove(ctx, sourcePath, targetPath)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected cancellation error, got %v", err)
+		}
+		if _, err := os.Stat(sourcePath); err != nil {
+			t.Error("Source file should stay in place")
+		}
+		if _, err := os.Stat(targetPath); !os.IsNotExist(err) {
+			t.Error("Partial target file should be removed")
+		}
+		if entries, _ := os.ReadDir(tempDir); len(entries) != 1 {
+			t.Errorf("Expected only the source left, got %v", entries)
+		}
+	})
+
+	t.Run("should copy through a temporary file and log it", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.txt")
+		targetDir := filepath.Join(tempDir, "target")
+		targetPath := filepath.Join(targetDir, "target.txt")
+		if err := os.Mkdir(targetDir, 0755); err != nil {
+			t.Fatalf("Failed to create target dir: %v", err)
+		}
+		if err := os.WriteFile(sourcePath, []byte("test content"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		journaled := &Local{journal: &journal{path: filepath.Join(tempDir, "journal.jsonl")}}
+		if err := journaled.copyAndRemove(context.Background(), sourcePath, targetPath); err != nil {
+			t.Fatalf("Failed to copy and remove file: %v", err)
+		}
+
+		if entries, _ := os.ReadDir(targetDir); len(entries) != 1 || entries[0].Name() != "target.txt" {
+			t.Errorf("Expected only target.txt in the target, got %v", entries)
+		}
+		if open, err := journaled.journal.unfinished(); err != nil || len(open) != 0 {
+			t.Errorf("Expected the copy logged as finished, got %v (%v)", open, err)
+		}
+	})
+
+	t.Run("should log the copy as finished when the source cannot be removed", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.txt")
+		targetPath := filepath.Join(tempDir, "target.txt")
+		if err := os.WriteFile(sourcePath, []byte("test content"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		// Replace the source by a directory that cannot be removed once
+		// the copy is placed.
+		place := func(oldpath, newpath string) error {
+			if err := renameNoReplace(oldpath, newpath); err != nil {
+				return err
+			}
+			if err := os.Remove(sourcePath); err != nil {
+				return err
+			}
+			return os.MkdirAll(filepath.Join(sourcePath, "busy"), 0755)
+		}
+
+		journaled := &Local{journal: &journal{path: filepath.Join(tempDir, "state", "journal.jsonl")}}
+		err := journaled.copyFile(context.Background(), sourcePath, targetPath, true, place)
+
+		if err == nil {
+			t.Fatal("Expected error when the source cannot be removed")
+		}
+		if content, _ := os.ReadFile(targetPath); string(content) != "test content" {
+			t.Error("Placed copy should stay in the target")
+		}
+		if open, err := journaled.journal.unfinished(); err != nil || len(open) != 0 {
+			t.Errorf("Expected the copy logged as finished, got %v (%v)", open, err)
+		}
+	})
+
+	t.Run("should record checksum of a verified copy", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.txt")
+		targetPath := filepath.Join(tempDir, "target.txt")
+		journalPath := filepath.Join(tempDir, "journal.jsonl")
+		if err := os.WriteFile(sourcePath, []byte("test content"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		verified := &Local{journal: &journal{path: journalPath}, verify: true}
+		if err := verified.copyAndRemove(context.Background(), sourcePath, targetPath); err != nil {
+			t.Fatalf("Failed to copy and remove file: %v", err)
+		}
+
+		logged, err := os.ReadFile(journalPath)
+		if err != nil {
+			t.Fatalf("Failed to read journal: %v", err)
+		}
+		// sha256 of "test content"
+		want := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		if !strings.Contains(string(logged), `"sha256":"`+want+`"`) {
+			t.Errorf("Expected checksum %s in journal, got %s", want, logged)
+		}
+		if !strings.Contains(string(logged), `"strategy":"`) {
+			t.Errorf("Expected copy strategy in journal, got %s", logged)
+		}
+	})
+
 	t.Run("should return error when source file cannot be opened", func(t *testing.T) {
-		err := copyAndRemove("/nonexistent/source.txt", "/some/target.txt")
+		err := local.copyAndRemove(context.Background(), "/nonexistent/source.txt", "/some/target.txt")
 		if err == nil {
 			t.Error("Expected error for non-existent source file")
 		}
@@ -433,7 +1217,7 @@ func Test_copyAndRemove(t *testing.T) {
 		// Use invalid target path
 		invalidTarget := "/root/invalid/target.txt"
 
-		err = copyAndRemove(sourcePath, invalidTarget)
+		err = local.copyAndRemove(context.Background(), sourcePath, invalidTarget)
 		if err == nil {
 			t.Error("Expected error for invalid target path")
 		}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/rycln/filer/internal/domain"
+	"golang.org/x/sys/unix"
+)
+
+// fillSysMetadata adds ownership, link count and birth time via statx.
+func fillSysMetadata(path string, meta *domain.FileMeta) {
+	var stx unix.Statx_t
+	mask := unix.STATX_UID | unix.STATX_GID | unix.STATX_NLINK | unix.STATX_BTIME
+	err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW, mask, &stx)
+	if err != nil {
+		return
+	}
+
+	meta.Links = uint64(stx.Nlink)
+	meta.Owner = lookupUser(stx.Uid)
+	meta.Group = lookupGroup(stx.Gid)
+	if stx.Mask&unix.STATX_BTIME != 0 {
+		meta.BirthTime = time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	}
+}
+
+func lookupUser(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(id); err == nil {
+		return u.Username
+	}
+	return id
+}
+
+func lookupGroup(gid uint32) string {
+	id := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(id); err == nil {
+		return g.Name
+	}
+	return id
+}
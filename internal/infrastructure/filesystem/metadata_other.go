@@ -0,0 +1,8 @@
+//go:build !linux
+
+package filesystem
+
+import "github.com/rycln/filer/internal/domain"
+
+// fillSysMetadata is a no-op where statx is unavailable.
+func fillSysMetadata(path string, meta *domain.FileMeta) {}
@@ -0,0 +1,93 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// preserveMetadata gives dest the ownership, permissions, extended
+// attributes and access and modification times of source, as stated by
+// info before it was read. Attributes that cannot be set are returned
+// instead of failing the copy.
+func preserveMetadata(source *os.File, info os.FileInfo, dest *os.File) []error {
+	st := info.Sys().(*syscall.Stat_t)
+
+	var errs []error
+	if err := dest.Chown(int(st.Uid), int(st.Gid)); err != nil {
+		errs = append(errs, fmt.Errorf("cannot set owner: %w", err))
+	}
+	// Set after the owner, since chown clears setuid and setgid bits.
+	if err := dest.Chmod(info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)); err != nil {
+		errs = append(errs, fmt.Errorf("cannot set permissions: %w", err))
+	}
+	errs = append(errs, copyXattrs(source, dest)...)
+
+	atime := time.Unix(st.Atim.Unix())
+	if err := os.Chtimes(dest.Name(), atime, info.ModTime()); err != nil {
+		errs = append(errs, fmt.Errorf("cannot set times: %w", err))
+	}
+
+	return errs
+}
+
+// copyXattrs copies the extended attributes of source to dest.
+// Nothing is copied from file systems without extended attributes.
+func copyXattrs(source, dest *os.File) []error {
+	names, err := xattrNames(int(source.Fd()))
+	if err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil
+		}
+		return []error{fmt.Errorf("cannot read extended attributes: %w", err)}
+	}
+
+	var errs []error
+	for _, name := range names {
+		value, err := xattr(int(source.Fd()), name)
+		if err == nil {
+			err = unix.Fsetxattr(int(dest.Fd()), name, value, 0)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot copy extended attribute %s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+func xattrNames(fd int) ([]string, error) {
+	size, err := unix.Flistxattr(fd, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Flistxattr(fd, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+func xattr(fd int, name string) ([]byte, error) {
+	size, err := unix.Fgetxattr(fd, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Fgetxattr(fd, name, buf)
+	return buf[:size], err
+}
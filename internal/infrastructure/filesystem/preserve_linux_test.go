@@ -0,0 +1,110 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func Test_preserveMetadata(t *testing.T) {
+	t.Run("should keep metadata of the source", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.jpg")
+		targetPath := filepath.Join(tempDir, "target.jpg")
+
+		if err := os.WriteFile(sourcePath, []byte("photo"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chmod(sourcePath, 0754); err != nil {
+			t.Fatalf("Failed to set mode: %v", err)
+		}
+		atime := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+		mtime := time.Date(2023, 7, 14, 18, 30, 0, 0, time.UTC)
+		if err := os.Chtimes(sourcePath, atime, mtime); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+		xattrs := true
+		if err := unix.Setxattr(sourcePath, "user.filer.test", []byte("kept"), 0); err != nil {
+			if !errors.Is(err, unix.ENOTSUP) {
+				t.Fatalf("Failed to set extended attribute: %v", err)
+			}
+			xattrs = false
+		}
+
+		before, err := os.Stat(sourcePath)
+		if err != nil {
+			t.Fatalf("Failed to stat source: %v", err)
+		}
+
+		local := &Local{}
+		if err := local.copyAndRemove(context.Background(), sourcePath, targetPath); err != nil {
+			t.Fatalf("Failed to copy and remove file: %v", err)
+		}
+
+		after, err := os.Stat(targetPath)
+		if err != nil {
+			t.Fatalf("Failed to stat target: %v", err)
+		}
+		if after.Mode() != before.Mode() {
+			t.Errorf("Expected mode %v, got %v", before.Mode(), after.Mode())
+		}
+		if !after.ModTime().Equal(before.ModTime()) {
+			t.Errorf("Expected modification time %v, got %v", before.ModTime(), after.ModTime())
+		}
+
+		beforeSys, afterSys := before.Sys().(*syscall.Stat_t), after.Sys().(*syscall.Stat_t)
+		if afterSys.Atim != beforeSys.Atim {
+			t.Errorf("Expected access time %v, got %v", beforeSys.Atim, afterSys.Atim)
+		}
+		if afterSys.Uid != beforeSys.Uid || afterSys.Gid != beforeSys.Gid {
+			t.Errorf("Expected owner %d:%d, got %d:%d", beforeSys.Uid, beforeSys.Gid, afterSys.Uid, afterSys.Gid)
+		}
+
+		if xattrs {
+			buf := make([]byte, 16)
+			n, err := unix.Getxattr(targetPath, "user.filer.test", buf)
+			if err != nil || string(buf[:n]) != "kept" {
+				t.Errorf("Expected extended attribute kept, got %q (%v)", buf[:n], err)
+			}
+		}
+		if warnings := local.Warnings(); len(warnings) != 0 {
+			t.Errorf("Expected no warnings, got %v", warnings)
+		}
+	})
+
+	t.Run("should warn about attributes that cannot be set", func(t *testing.T) {
+		tempDir := t.TempDir()
+		sourcePath := filepath.Join(tempDir, "source.jpg")
+		if err := os.WriteFile(sourcePath, []byte("photo"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		source, err := os.Open(sourcePath)
+		if err != nil {
+			t.Fatalf("Failed to open source: %v", err)
+		}
+		defer source.Close()
+		dest, err := os.Create(filepath.Join(tempDir, "target.jpg"))
+		if err != nil {
+			t.Fatalf("Failed to create target: %v", err)
+		}
+		dest.Close()
+
+		info, err := source.Stat()
+		if err != nil {
+			t.Fatalf("Failed to stat source: %v", err)
+		}
+
+		if errs := preserveMetadata(source, info, dest); len(errs) == 0 {
+			t.Error("Expected warnings for a closed target file")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// preserveMetadata gives dest the permissions and modification time of
+// source, as stated by info. Ownership and extended attributes are kept on Linux only.
+func preserveMetadata(source *os.File, info os.FileInfo, dest *os.File) []error {
+
+	var errs []error
+	if err := dest.Chmod(info.Mode().Perm()); err != nil {
+		errs = append(errs, fmt.Errorf("cannot set permissions: %w", err))
+	}
+	if err := os.Chtimes(dest.Name(), time.Time{}, info.ModTime()); err != nil {
+		errs = append(errs, fmt.Errorf("cannot set times: %w", err))
+	}
+	return errs
+}
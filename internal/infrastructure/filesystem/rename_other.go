@@ -0,0 +1,8 @@
+//go:build !linux
+
+package filesystem
+
+// renameNoReplace renames oldpath to newpath unless newpath exists.
+func renameNoReplace(oldpath, newpath string) error {
+	return linkRename(oldpath, newpath)
+}
@@ -0,0 +1,15 @@
+//go:build linux
+
+package filesystem
+
+import "golang.org/x/sys/unix"
+
+// freeSpace returns the bytes available to the user on the file system
+// holding dir.
+func freeSpace(dir string) (int64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * st.Bsize, nil
+}
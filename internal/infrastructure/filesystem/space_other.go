@@ -0,0 +1,8 @@
+//go:build !linux
+
+package filesystem
+
+// freeSpace is unknown where statfs is unavailable.
+func freeSpace(dir string) (int64, error) {
+	return -1, nil
+}
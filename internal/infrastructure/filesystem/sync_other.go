@@ -0,0 +1,6 @@
+//go:build !unix
+
+package filesystem
+
+// syncDir is a no-op where directories cannot be synced.
+func syncDir(dir string) error { return nil }
@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NewMatcher builds a filename predicate for a live filter query.
+// The query is a regular expression when regex is set, otherwise
+// a fuzzy pattern. Returns nil for an empty query.
+func NewMatcher(query string, regex bool) (func(string) bool, error) {
+	if query == "" {
+		return nil, nil
+	}
+
+	if regex {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+
+	return func(filename string) bool {
+		return FuzzyMatch(query, filename)
+	}, nil
+}
+
+// FuzzyMatch reports whether all characters of pattern occur in s
+// in the same order. Matching ignores case.
+func FuzzyMatch(pattern, s string) bool {
+	rest := []rune(strings.ToLower(s))
+	for _, p := range strings.ToLower(pattern) {
+		if unicode.IsSpace(p) {
+			continue
+		}
+		i := indexRune(rest, p)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return true
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package filter
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"", "photo.jpg", true},
+		{"pjpg", "photo.jpg", true},
+		{"PHO", "photo.jpg", true},
+		{"img 24", "IMG_2024.png", true},
+		{"jpgp", "photo.jpg", false},
+		{"x", "photo.jpg", false},
+	}
+
+	for _, tt := range tests {
+		t.Run("should match "+tt.pattern+" against "+tt.name, func(t *testing.T) {
+			if got := FuzzyMatch(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	t.Run("should return nil for empty query", func(t *testing.T) {
+		match, err := NewMatcher("", true)
+		if err != nil || match != nil {
+			t.Errorf("Expected nil matcher and error, got %v", err)
+		}
+	})
+
+	t.Run("should match regular expression", func(t *testing.T) {
+		match, err := NewMatcher(`^\d+\.jpg$`, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !match("2024.jpg") || match("a2024.jpg") {
+			t.Error("Regex matcher gave wrong result")
+		}
+	})
+
+	t.Run("should return error for invalid regex", func(t *testing.T) {
+		if _, err := NewMatcher("[", true); err == nil {
+			t.Error("Expected error for invalid pattern")
+		}
+	})
+
+	t.Run("should match fuzzily without regex", func(t *testing.T) {
+		match, err := NewMatcher("[", false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !match("a[1].txt") || match("a.txt") {
+			t.Error("Fuzzy matcher gave wrong result")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rycln/filer/internal/domain"
+)
+
+// metaFields extract the value compared by a condition from metadata.
+// Yes/no fields compare "yes" or "no".
+var metaFields = map[string]func(domain.FileMeta) string{
+	"camera": func(m domain.FileMeta) string { return m.Camera },
+	"taken": func(m domain.FileMeta) string {
+		if m.Taken.IsZero() {
+			return ""
+		}
+		return m.Taken.Format(domain.DefaultDateLayout)
+	},
+	"orientation": orientation,
+	"gps":         func(m domain.FileMeta) string { return yesNo(m.GPS) },
+	"image":       func(m domain.FileMeta) string { return yesNo(m.IsImage()) },
+}
+
+// condition compares one metadata field against a value.
+type condition struct {
+	field  string
+	value  string
+	negate bool
+}
+
+// MetaFilter keeps files whose metadata meets all conditions.
+// Files whose metadata cannot be read are dropped.
+type MetaFilter struct {
+	conds    []condition
+	metadata func(string) (domain.FileMeta, error)
+}
+
+// NewMetaFilter parses conditions like "camera=pixel" or "gps!=yes".
+// Metadata of each file is read with metadata.
+func NewMetaFilter(exprs []string, metadata func(string) (domain.FileMeta, error)) (*MetaFilter, error) {
+	f := &MetaFilter{metadata: metadata}
+
+	for _, expr := range exprs {
+		field, value, ok := strings.Cut(expr, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid metadata condition %q, expected field=value", expr)
+		}
+
+		cond := condition{field: strings.TrimSpace(field), value: strings.ToLower(strings.TrimSpace(value))}
+		if name, ok := strings.CutSuffix(cond.field, "!"); ok {
+			cond.field, cond.negate = name, true
+		}
+		if _, ok := metaFields[cond.field]; !ok {
+			return nil, fmt.Errorf("unknown metadata field: %s", cond.field)
+		}
+
+		f.conds = append(f.conds, cond)
+	}
+
+	return f, nil
+}
+
+func (f *MetaFilter) Filter(filenames []string) ([]string, error) {
+	if len(f.conds) == 0 {
+		return filenames, nil
+	}
+
+	var filtered []string
+
+	for _, filename := range filenames {
+		meta, err := f.metadata(filename)
+		if err != nil {
+			continue
+		}
+		if f.match(meta) {
+			filtered = append(filtered, filename)
+		}
+	}
+
+	return filtered, nil
+}
+
+// match reports whether meta meets all conditions.
+// Camera and taken match case-insensitive prefixes and substrings,
+// other fields match exactly.
+func (f *MetaFilter) match(meta domain.FileMeta) bool {
+	for _, cond := range f.conds {
+		got := strings.ToLower(metaFields[cond.field](meta))
+
+		var ok bool
+		switch cond.field {
+		case "camera":
+			ok = strings.Contains(got, cond.value)
+		case "taken":
+			ok = got != "" && strings.HasPrefix(got, cond.value)
+		default:
+			ok = got == cond.value
+		}
+
+		if ok == cond.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// orientation classifies an image as landscape, portrait or square.
+func orientation(m domain.FileMeta) string {
+	switch {
+	case m.Width == 0 || m.Height == 0:
+		return ""
+	case m.Width > m.Height:
+		return "landscape"
+	case m.Width < m.Height:
+		return "portrait"
+	}
+	return "square"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
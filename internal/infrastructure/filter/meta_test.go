@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rycln/filer/internal/domain"
+)
+
+func TestMetaFilter(t *testing.T) {
+	metas := map[string]domain.FileMeta{
+		"pixel.jpg": {Camera: "Google Pixel 7", GPS: true, Width: 4080, Height: 3072,
+			Taken: time.Date(2024, 6, 12, 10, 0, 0, 0, time.Local)},
+		"canon.cr2": {Camera: "Canon EOS R5", Width: 4000, Height: 6000,
+			Taken: time.Date(2023, 1, 5, 10, 0, 0, 0, time.Local)},
+		"notes.txt": {},
+	}
+	metadata := func(name string) (domain.FileMeta, error) {
+		meta, ok := metas[name]
+		if !ok {
+			return domain.FileMeta{}, errors.New("no such file")
+		}
+		return meta, nil
+	}
+	files := []string{"canon.cr2", "missing.jpg", "notes.txt", "pixel.jpg"}
+
+	tests := []struct {
+		name  string
+		exprs []string
+		want  []string
+	}{
+		{"camera substring", []string{"camera=pixel"}, []string{"pixel.jpg"}},
+		{"no gps", []string{"gps=no"}, []string{"canon.cr2", "notes.txt"}},
+		{"negated camera", []string{"camera!=pixel", "image=yes"}, []string{"canon.cr2"}},
+		{"taken prefix", []string{"taken=2024-06"}, []string{"pixel.jpg"}},
+		{"orientation", []string{"orientation=portrait"}, []string{"canon.cr2"}},
+		{"no conditions", nil, files},
+	}
+
+	for _, tt := range tests {
+		t.Run("should filter by "+tt.name, func(t *testing.T) {
+			f, err := NewMetaFilter(tt.exprs, metadata)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got, err := f.Filter(files)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	for _, expr := range []string{"camera", "iso=100", "gps="} {
+		t.Run("should reject "+expr, func(t *testing.T) {
+			if _, err := NewMetaFilter([]string{expr}, metadata); err == nil {
+				t.Error("Expected error for invalid condition")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package lock keeps two filer sessions from sorting the same directory.
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Name is the lock file created in the locked directory.
+const Name = ".filer.lock"
+
+// attempts bounds how often a lock removed or found stale under our feet
+// is tried again.
+const attempts = 3
+
+// Holder identifies the session holding a lock.
+type Holder struct {
+	PID   int       `json:"pid"`
+	Host  string    `json:"host"`
+	Since time.Time `json:"since"`
+}
+
+// HeldError reports a directory locked by another session.
+type HeldError struct {
+	Dir    string
+	Holder Holder
+}
+
+func (e *HeldError) Error() string {
+	if e.Holder.PID == 0 {
+		return fmt.Sprintf("%s is in use by another filer session", e.Dir)
+	}
+	return fmt.Sprintf("%s is in use by filer (pid %d on %s since %s)",
+		e.Dir, e.Holder.PID, e.Holder.Host, e.Holder.Since.Format("2006-01-02 15:04"))
+}
+
+// Lock is an advisory lock on a directory, held until released or the
+// process exits.
+type Lock struct {
+	f    *os.File
+	path string
+}
+
+// Acquire locks dir for this session. The lock file is created in dir so
+// sessions on other hosts sharing it see the lock; where dir cannot be
+// written, it is kept in stateDir instead. Fails with *HeldError when
+// another session holds the lock.
+func Acquire(dir, stateDir string) (*Lock, error) {
+	l, err := acquire(filepath.Join(dir, Name), dir)
+	var held *HeldError
+	if err == nil || errors.As(err, &held) || stateDir == "" {
+		return l, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(stateDir, "locks"), 0755); err != nil {
+		return nil, err
+	}
+	return acquire(statePath(dir, stateDir), dir)
+}
+
+// statePath names the lock of dir kept in stateDir.
+func statePath(dir, stateDir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(stateDir, "locks", hex.EncodeToString(sum[:8])+".lock")
+}
+
+// Release removes the lock file and unlocks the directory.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	err := os.Remove(l.path)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Running reports whether the session with pid on host may still run.
+// Sessions on other hosts cannot be checked and count as running.
+func Running(pid int, host string) bool {
+	current, err := os.Hostname()
+	if err != nil || host != current {
+		return true
+	}
+	return pid > 0 && alive(pid)
+}
+
+func readHolder(path string) (Holder, error) {
+	var h Holder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h, err
+	}
+	return h, json.Unmarshal(data, &h)
+}
+
+// writeHolder replaces the contents of f, possibly left by a crashed
+// session, with this session.
+func writeHolder(f *os.File) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+	data, err := json.Marshal(Holder{PID: os.Getpid(), Host: host, Since: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(append(data, '\n'), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,59 @@
+//go:build !unix
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// acquire creates the lock file at path, which exists only while a
+// session holds it. Without advisory locks a file left by a crashed
+// session is recognised by its holder no longer running and removed.
+func acquire(path, dir string) (*Lock, error) {
+	for range attempts {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			if err := writeHolder(f); err != nil {
+				f.Close()
+				os.Remove(path)
+				return nil, err
+			}
+			return &Lock{f: f, path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		holder, err := readHolder(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // released meanwhile
+		}
+		if err != nil || !holder.stale() {
+			return nil, &HeldError{Dir: dir, Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("failed to lock %s: lock file keeps changing", dir)
+}
+
+// stale reports whether h is a session on this host that no longer runs.
+// Holders on other hosts cannot be checked and are trusted.
+func (h Holder) stale() bool {
+	return h.PID > 0 && h.PID != os.Getpid() && !Running(h.PID, h.Host)
+}
+
+// alive reports whether a process with pid runs on this host. Finding a
+// process fails for processes that are gone on platforms without signals.
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,180 @@
+//go:build unix
+
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestAcquire(t *testing.T) {
+	hostname := func(t *testing.T) string {
+		host, err := os.Hostname()
+		if err != nil {
+			t.Skipf("Host name unknown: %v", err)
+		}
+		return host
+	}
+
+	writeLock := func(t *testing.T, path string, h Holder) {
+		data, err := json.Marshal(h)
+		if err != nil {
+			t.Fatalf("Failed to encode holder: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("Failed to write lock file: %v", err)
+		}
+	}
+
+	t.Run("should record this session in the lock file", func(t *testing.T) {
+		dir := t.TempDir()
+
+		l, err := Acquire(dir, "")
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
+		}
+		defer l.Release()
+
+		holder, err := readHolder(filepath.Join(dir, Name))
+		if err != nil {
+			t.Fatalf("Failed to read lock file: %v", err)
+		}
+		if holder.PID != os.Getpid() {
+			t.Errorf("Expected pid %d, got %d", os.Getpid(), holder.PID)
+		}
+	})
+
+	t.Run("should refuse a second session naming the holder", func(t *testing.T) {
+		dir := t.TempDir()
+		l, err := Acquire(dir, "")
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
+		}
+		defer l.Release()
+
+		_, err = Acquire(dir, t.TempDir())
+
+		var held *HeldError
+		if !errors.As(err, &held) {
+			t.Fatalf("Expected HeldError, got %v", err)
+		}
+		host, _ := os.Hostname()
+		if !strings.Contains(err.Error(), "pid "+strconv.Itoa(os.Getpid())+" on "+host) {
+			t.Errorf("Expected holder pid and host in %q", err)
+		}
+	})
+
+	t.Run("should take over the lock of a session that exited", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLock(t, filepath.Join(dir, Name), Holder{PID: 1 << 30, Host: hostname(t), Since: time.Now()})
+
+		l, err := Acquire(dir, "")
+		if err != nil {
+			t.Fatalf("Expected stale lock to be taken over, got %v", err)
+		}
+		defer l.Release()
+
+		if holder, _ := readHolder(filepath.Join(dir, Name)); holder.PID != os.Getpid() {
+			t.Errorf("Expected lock file to name this session, got %+v", holder)
+		}
+	})
+
+	t.Run("should never remove a lock file held by another process", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, Name)
+		writeLock(t, path, Holder{PID: 1 << 30, Host: hostname(t), Since: time.Now()})
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open lock file: %v", err)
+		}
+		defer f.Close()
+		if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+			t.Fatalf("Failed to lock file: %v", err)
+		}
+
+		_, err = Acquire(dir, "")
+
+		var held *HeldError
+		if !errors.As(err, &held) {
+			t.Fatalf("Expected HeldError, got %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Lock file of the holder should stay: %v", err)
+		}
+	})
+
+	t.Run("should remove the lock file on release", func(t *testing.T) {
+		dir := t.TempDir()
+		l, err := Acquire(dir, "")
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
+		}
+
+		if err := l.Release(); err != nil {
+			t.Fatalf("Failed to release lock: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, Name)); !os.IsNotExist(err) {
+			t.Error("Lock file should be removed")
+		}
+		again, err := Acquire(dir, "")
+		if err != nil {
+			t.Fatalf("Expected lock to be free again, got %v", err)
+		}
+		again.Release()
+	})
+
+	t.Run("should keep the lock in the state dir when the directory is read-only", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		dir, stateDir := t.TempDir(), t.TempDir()
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatalf("Failed to make directory read-only: %v", err)
+		}
+		defer os.Chmod(dir, 0755)
+
+		l, err := Acquire(dir, stateDir)
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
+		}
+		defer l.Release()
+
+		if _, err := os.Stat(statePath(dir, stateDir)); err != nil {
+			t.Errorf("Expected lock file in state dir: %v", err)
+		}
+	})
+}
+
+func TestRunning(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("Host name unknown: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		pid  int
+		host string
+		want bool
+	}{
+		{"running session", os.Getppid(), host, true},
+		{"exited session", 1 << 30, host, false},
+		{"session on another host", 1 << 30, host + "-other", true},
+		{"unknown session", 0, host, false},
+	}
+
+	for _, tt := range tests {
+		if got := Running(tt.pid, tt.host); got != tt.want {
+			t.Errorf("%s: Running() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
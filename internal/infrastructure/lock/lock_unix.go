@@ -0,0 +1,70 @@
+//go:build unix
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// acquire takes an advisory lock on the file at path. The kernel drops
+// the lock when its holder exits, so a file left by a crashed session is
+// simply locked again and its holder overwritten. A file locked by
+// another process is never removed, whatever holder it names.
+func acquire(path, dir string) (*Lock, error) {
+	for range attempts {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		err = unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			f.Close()
+			holder, err := readHolder(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue // released meanwhile
+			}
+			return nil, &HeldError{Dir: dir, Holder: holder}
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		// A session releasing the lock removes the file; ours may be gone.
+		if !samePath(f, path) {
+			f.Close()
+			continue
+		}
+
+		if err := writeHolder(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &Lock{f: f, path: path}, nil
+	}
+
+	return nil, fmt.Errorf("failed to lock %s: lock file keeps changing", dir)
+}
+
+// alive reports whether a process with pid runs on this host.
+func alive(pid int) bool {
+	return !errors.Is(unix.Kill(pid, 0), unix.ESRCH)
+}
+
+// samePath reports whether f is still the file at path.
+func samePath(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/rycln/filer/internal/domain"
+)
+
+type counts struct {
+	Kept    int `json:"kept"`
+	Deleted int `json:"deleted"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+type sessionReport struct {
+	counts
+	BytesMoved         int64             `json:"bytes_moved"`
+	BytesFreed         int64             `json:"bytes_freed"`
+	ElapsedSeconds     float64           `json:"elapsed_seconds"`
+	SecondsPerDecision float64           `json:"seconds_per_decision"`
+	Extensions         map[string]counts `json:"extensions"`
+}
+
+// WriteJSON saves session statistics as indented JSON to path.
+func WriteJSON(path string, stats *domain.SessionStats) error {
+	r := sessionReport{
+		counts:             toCounts(stats.DecisionCounts),
+		BytesMoved:         stats.BytesMoved,
+		BytesFreed:         stats.BytesFreed,
+		ElapsedSeconds:     stats.Elapsed().Seconds(),
+		SecondsPerDecision: stats.AverageDecision().Seconds(),
+		Extensions:         make(map[string]counts),
+	}
+
+	for _, ext := range stats.Extensions() {
+		r.Extensions[ext] = toCounts(stats.ByExtension(ext))
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func toCounts(c domain.DecisionCounts) counts {
+	return counts{
+		Kept:    c.Kept,
+		Deleted: c.Deleted,
+		Skipped: c.Skipped,
+		Failed:  c.Failed,
+	}
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rycln/filer/internal/domain"
+)
+
+func TestWriteJSON(t *testing.T) {
+	t.Run("should write statistics as json", func(t *testing.T) {
+		tempDir, err := os.MkdirTemp("", "test_report")
+		if err != nil {
+			t.Fatalf("Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		start := time.Now()
+		stats := domain.NewSessionStats(start)
+		stats.RecordKeep("a.jpg", 100)
+		stats.RecordDelete("b.log", 40)
+		stats.Finish(start.Add(4 * time.Second))
+
+		path := filepath.Join(tempDir, "report.json")
+		err = WriteJSON(path, stats)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read report: %v", err)
+		}
+
+		var got sessionReport
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Report is not valid json: %v", err)
+		}
+		if got.Kept != 1 || got.Deleted != 1 {
+			t.Errorf("Expected 1 kept and 1 deleted, got %+v", got.counts)
+		}
+		if got.BytesMoved != 100 || got.BytesFreed != 40 {
+			t.Errorf("Expected 100 moved and 40 freed, got %d and %d", got.BytesMoved, got.BytesFreed)
+		}
+		if got.SecondsPerDecision != 2 {
+			t.Errorf("Expected 2 seconds per decision, got %v", got.SecondsPerDecision)
+		}
+		if got.Extensions[".jpg"].Kept != 1 {
+			t.Errorf("Expected .jpg breakdown, got %v", got.Extensions)
+		}
+	})
+
+	t.Run("should return error for unwritable path", func(t *testing.T) {
+		stats := domain.NewSessionStats(time.Now())
+
+		err := WriteJSON(filepath.Join(os.DevNull, "report.json"), stats)
+		if err == nil {
+			t.Error("Expected error for invalid path")
+		}
+	})
+}
@@ -1,14 +1,81 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rycln/filer/internal/domain"
+	"github.com/rycln/filer/internal/infrastructure/filter"
 )
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.loadMeta(), m.waitFiles(), m.loadSpace())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	return m.settle(cmd)
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		return m, nil
+	}
+
+	if msg, ok := msg.(MetaMsg); ok {
+		m.metas[msg.File] = msg
+		return m, nil
+	}
+
+	if msg, ok := msg.(SpaceMsg); ok {
+		if msg.Err == nil {
+			m.space = msg.Space
+		}
+		return m, nil
+	}
+
+	if msg, ok := msg.(FilesMsg); ok {
+		m.batch.Append(msg.Groups)
+		return m, m.waitFiles()
+	}
+
+	if msg, ok := msg.(ProgressMsg); ok {
+		if m.queue.running(msg.File) {
+			m.copies[msg.File] = msg
+		}
+		return m, m.waitProgress()
+	}
+
+	switch msg := msg.(type) {
+	case SuccessMsg:
+		op := m.queue.remove(msg.File)
+		delete(m.copies, msg.File)
+		return m.recordFile(msg.File, op.act.status(), msg.Size), m.loadSpace()
+	case ErrorMsg:
+		op := m.queue.remove(msg.File)
+		delete(m.copies, msg.File)
+		if errors.Is(msg.Err, context.Canceled) {
+			return m.recordFile(msg.File, domain.StatusSkipped, 0), nil
+		}
+		return m.fail(op, msg.Err), nil
+	case TickMsg:
+		if m.queue.len() == 0 {
+			m.ticking = false
+			return m, nil
+		}
+		return m, tick()
+	}
+
 	switch m.state {
 	case FileManageState:
 		return handleFileManageState(m, msg)
@@ -18,94 +85,687 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return handleEndState(m, msg)
 	case ErrorState:
 		return handleErrorState(m, msg)
+	case BulkState:
+		return handleBulkState(m, msg)
+	case ReportState:
+		return handleReportState(m, msg)
+	case ConfirmState:
+		return handleConfirmState(m, msg)
+	case WaitState:
+		return handleWaitState(m, msg)
 	}
 
 	return m, nil
 }
 
 func handleFileManageState(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	if m.querying {
+		return handleFilterInput(m, msg)
+	}
+	if m.marking {
+		return handleMarkInput(m, msg)
+	}
+	if m.naming {
+		return handleNameInput(m, msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC:
-			return m, tea.Quit
-		case tea.KeyRunes:
-			switch msg.String() {
-			case "q":
-				return m, tea.Quit
-			case "k":
-				m.state = ProcessingState
-				return m, m.keep()
-			case "d":
-				m.state = ProcessingState
-				return m, m.delete()
-			case "s":
-				m.batch.NextFile()
-				if m.batch.IsComplete() {
-					m.state = EndState
-				} else {
-					m.state = FileManageState
-				}
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.keys.List):
+			m.showList = !m.showList
+		case key.Matches(msg, m.keys.Filter):
+			m.querying = true
+			return m, m.query.Focus()
+		case key.Matches(msg, m.keys.Clear) && m.visual:
+			m.visual = false
+		case key.Matches(msg, m.keys.Clear) && m.batch.Filtered():
+			return m.clearFilter()
+		case key.Matches(msg, m.keys.Mark) && m.batch.TotalFiles() > 0:
+			m.batch.ToggleMark(m.batch.Cursor())
+			return m.moveTo(m.batch.Cursor() + 1)
+		case key.Matches(msg, m.keys.Visual) && m.visual:
+			m = m.markRange()
+		case key.Matches(msg, m.keys.Visual) && m.batch.TotalFiles() > 0:
+			m.visual = true
+			m.anchor = m.batch.Cursor()
+		case key.Matches(msg, m.keys.Match):
+			m.marking = true
+			return m, m.pattern.Focus()
+		case key.Matches(msg, m.keys.Unmark):
+			m.visual = false
+			m.batch.ClearMarks()
+		case key.Matches(msg, m.keys.Skip) && m.selecting():
+			if m.visual {
+				m = m.markRange()
+			}
+			return m.skipMarked()
+		case m.isAction(msg) && m.selecting():
+			if m.visual {
+				m = m.markRange()
 			}
+			return m.startBulk(m.actionFor(msg), m.batch.MarkedFiles())
+		case key.Matches(msg, m.keys.Up):
+			return m.moveTo(m.batch.Cursor() - 1)
+		case key.Matches(msg, m.keys.Down):
+			return m.moveTo(m.batch.Cursor() + 1)
+		case key.Matches(msg, m.keys.Top):
+			return m.moveTo(0)
+		case key.Matches(msg, m.keys.Bottom):
+			return m.moveTo(m.batch.TotalFiles() - 1)
+		case m.batch.CurrentFile() == "", !m.batch.CurrentStatus().Decidable():
+			return m, nil
+		case key.Matches(msg, m.keys.KeepAll):
+			return m.askSimilar(m.keepAs), nil
+		case key.Matches(msg, m.keys.DeleteAll):
+			return m.askSimilar(deleteAction), nil
+		case key.Matches(msg, m.keys.Rename):
+			m.naming = true
+			m.nameErr = nil
+			m.name.SetValue(m.batch.CurrentFile())
+			m.name.CursorEnd()
+			return m, m.name.Focus()
+		case m.isAction(msg):
+			return m.decide(m.actionFor(msg))
+		case key.Matches(msg, m.keys.Skip):
+			m = m.record(domain.StatusSkipped, 0)
+			return m.nextFile()
 		}
 	}
 
 	return m, nil
 }
 
-func (m Model) keep() tea.Cmd {
-	return func() tea.Msg {
-		err := m.manager.Keep(m.batch.CurrentFile())
-		if err != nil {
-			return ErrorMsg{
-				Err: err,
+// handleFilterInput edits the filter query while the input has focus.
+// The view is refiltered on every change.
+func handleFilterInput(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Apply):
+			m.querying = false
+			m.query.Blur()
+			return m, nil
+		case key.Matches(msg, m.keys.Clear):
+			return m.clearFilter()
+		case key.Matches(msg, m.keys.Mode):
+			m.regex = !m.regex
+			return m.applyFilter()
+		}
+	}
+
+	prev := m.query.Value()
+	var cmd tea.Cmd
+	m.query, cmd = m.query.Update(msg)
+	if m.query.Value() == prev {
+		return m, cmd
+	}
+
+	m, metaCmd := m.applyFilter()
+	return m, tea.Batch(cmd, metaCmd)
+}
+
+// applyFilter filters the batch by the current query.
+// An invalid pattern is reported and leaves the view unchanged.
+func (m Model) applyFilter() (Model, tea.Cmd) {
+	match, err := filter.NewMatcher(m.query.Value(), m.regex)
+	m.queryErr = err
+	if err != nil {
+		return m, nil
+	}
+
+	prev := m.batch.CurrentFile()
+	m.visual = false
+	m.batch.Filter(match)
+	if m.batch.CurrentFile() == prev {
+		return m, nil
+	}
+	return m, m.loadMeta()
+}
+
+// clearFilter closes the filter input and shows all files again.
+func (m Model) clearFilter() (Model, tea.Cmd) {
+	m.querying = false
+	m.query.Blur()
+	m.query.Reset()
+	return m.applyFilter()
+}
+
+// handleMarkInput edits the pattern of files to mark.
+// Visible files matching it are marked when the pattern is applied.
+func handleMarkInput(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Apply):
+			return m.markMatching(), nil
+		case key.Matches(msg, m.keys.Clear):
+			return m.closeMarkInput(), nil
+		case key.Matches(msg, m.keys.Mode):
+			m.regex = !m.regex
+			_, m.matchErr = filter.NewMatcher(m.pattern.Value(), m.regex)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.pattern, cmd = m.pattern.Update(msg)
+	_, m.matchErr = filter.NewMatcher(m.pattern.Value(), m.regex)
+	return m, cmd
+}
+
+// handleNameInput edits the new name of the current file.
+// The name is a template checked on every change.
+func handleNameInput(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Apply):
+			if _, err := m.expandName(m.batch.CurrentFile(), m.currentMeta()); err != nil {
+				m.nameErr = err
+				return m, nil
 			}
+			m.naming = false
+			m.name.Blur()
+			m.newName = m.name.Value()
+			return m.decide(renameAction)
+		case key.Matches(msg, m.keys.Clear):
+			m.naming = false
+			m.name.Blur()
+			return m, nil
 		}
+	}
+
+	var cmd tea.Cmd
+	m.name, cmd = m.name.Update(msg)
+	m.newName = m.name.Value()
+	_, m.nameErr = m.expandName(m.batch.CurrentFile(), m.currentMeta())
+	return m, cmd
+}
+
+// currentMeta returns the loaded metadata of the current file,
+// or zero values while it is still loading.
+func (m Model) currentMeta() domain.FileMeta {
+	return m.metas[m.batch.CurrentFile()].Meta
+}
+
+// expandName expands the rename template for filename and validates it.
+// Counts the file as the next one renamed in this session.
+func (m Model) expandName(filename string, meta domain.FileMeta) (string, error) {
+	name, err := domain.ExpandName(m.newName, domain.NameVars{
+		Name:    filename,
+		ModTime: meta.ModTime,
+		Counter: m.renames + 1,
+		Parent:  meta.Parent,
+		Date:    meta.Taken,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return name, domain.ValidateName(name)
+}
+
+// markMatching marks visible files matching the pattern.
+// Stays in the input when the pattern is invalid.
+func (m Model) markMatching() Model {
+	match, err := filter.NewMatcher(m.pattern.Value(), m.regex)
+	if err != nil {
+		m.matchErr = err
+		return m
+	}
 
-		return SuccessMsg{}
+	for i := range m.batch.TotalFiles() {
+		if match == nil || match(m.batch.File(i)) {
+			m.batch.Mark(i)
+		}
 	}
+	return m.closeMarkInput()
+}
+
+func (m Model) closeMarkInput() Model {
+	m.marking = false
+	m.matchErr = nil
+	m.pattern.Blur()
+	m.pattern.Reset()
+	return m
+}
+
+// markRange marks the files between the visual anchor and the cursor.
+func (m Model) markRange() Model {
+	from, to := m.visualRange()
+	for i := from; i <= to; i++ {
+		m.batch.Mark(i)
+	}
+	m.visual = false
+	return m
+}
+
+// visualRange returns the view positions selected in visual mode.
+func (m Model) visualRange() (int, int) {
+	cur := m.batch.Cursor()
+	return min(m.anchor, cur), max(m.anchor, cur)
+}
+
+// selecting reports whether actions apply to marked files.
+func (m Model) selecting() bool {
+	return m.visual || len(m.batch.MarkedFiles()) > 0
+}
+
+// moveTo places the cursor on file i and loads its metadata.
+func (m Model) moveTo(i int) (Model, tea.Cmd) {
+	m.batch.MoveTo(i)
+	return m, m.loadMeta()
+}
+
+// isAction reports whether msg starts a file operation.
+func (m Model) isAction(msg tea.KeyMsg) bool {
+	return key.Matches(msg, m.keys.Keep, m.keys.Copy, m.keys.Symlink, m.keys.Hardlink, m.keys.Delete)
+}
+
+// actionFor returns the file operation started by msg.
+// The keep key runs the action chosen with --keep-mode.
+func (m Model) actionFor(msg tea.KeyMsg) action {
+	switch {
+	case key.Matches(msg, m.keys.Delete):
+		return deleteAction
+	case key.Matches(msg, m.keys.Copy):
+		return copyAction
+	case key.Matches(msg, m.keys.Symlink):
+		return symlinkAction
+	case key.Matches(msg, m.keys.Hardlink):
+		return hardlinkAction
+	}
+	return m.keepAs
+}
+
+func (m Model) keep() tea.Cmd {
+	return m.process(keepAction, m.batch.CurrentFile())
 }
 
 func (m Model) delete() tea.Cmd {
+	return m.process(deleteAction, m.batch.CurrentFile())
+}
+
+// decide queues an operation on the current file and moves on
+// without waiting for it.
+func (m Model) decide(act action) (Model, tea.Cmd) {
+	m, cmd := m.enqueue(act, m.batch.CurrentFile())
+	m, next := m.nextFile()
+	return m, tea.Batch(cmd, next)
+}
+
+// enqueue queues an operation on filename.
+// Renames take their counter now so queued renames get distinct numbers.
+func (m Model) enqueue(act action, filename string) (Model, tea.Cmd) {
+	op := operation{file: filename, act: act, run: m.process(act, filename)}
+	if act == renameAction {
+		m.renames++
+	}
+	return m.schedule(op)
+}
+
+// schedule marks the file of op queued and starts refreshing the status
+// line. The command of op waits for a free worker.
+func (m Model) schedule(op operation) (Model, tea.Cmd) {
+	m.queue.add(op)
+	m = m.recordFile(op.file, domain.StatusQueued, 0)
+
+	cmds := []tea.Cmd{op.run}
+	if !m.ticking {
+		m.ticking = true
+		cmds = append(cmds, tick())
+	}
+	if !m.listening {
+		m.listening = true
+		cmds = append(cmds, m.waitProgress())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// process runs an operation on filename once a queue worker is free.
+// Copied bytes are reported to the view while it runs.
+func (m Model) process(act action, filename string) tea.Cmd {
 	return func() tea.Msg {
-		err := m.manager.Delete(m.batch.CurrentFile())
+		if err := m.queue.acquire(m.ctx, filename); err != nil {
+			return ErrorMsg{File: filename, Err: err}
+		}
+		size, err := m.operate(m.ctx, act, filename, m.progress)
+		m.queue.release()
 		if err != nil {
 			return ErrorMsg{
-				Err: err,
+				File: filename,
+				Err:  err,
 			}
 		}
 
-		return SuccessMsg{}
+		return SuccessMsg{File: filename, Size: size}
+	}
+}
+
+// operate runs a file operation and returns the bytes it moved or freed.
+// The size is read first since a moved file is gone afterwards. Files
+// kept in place, copied or linked move nothing.
+// Progress of copies goes to progress unless it is nil.
+func (m Model) operate(ctx context.Context, act action, filename string, progress chan<- ProgressMsg) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	switch act {
+	case symlinkAction:
+		return 0, m.manager.Symlink(filename)
+	case hardlinkAction:
+		return 0, m.manager.Hardlink(filename)
+	}
+
+	size, _ := m.manager.Size(filename)
+	if progress != nil {
+		ctx = domain.WithProgress(ctx, reporter(progress, filename, size))
+	}
+	moved := size
+	if !m.target {
+		moved = 0
+	}
+
+	switch act {
+	case renameAction:
+		meta, _ := m.manager.Metadata(filename)
+		newName, err := m.expandName(filename, meta)
+		if err != nil {
+			return 0, err
+		}
+		return moved, m.manager.KeepAs(ctx, filename, newName)
+	case deleteAction:
+		return size, m.manager.Delete(filename)
+	case copyAction:
+		return 0, m.manager.Copy(ctx, filename)
+	}
+	return moved, m.manager.Keep(ctx, filename)
+}
+
+// fail keeps a failed operation for the error screen.
+// Errors of an ignored kind give up on the file right away.
+func (m Model) fail(op operation, err error) Model {
+	f := fault{op: op, err: err}
+	if m.ignored[errorKind(err)] {
+		return m.giveUp(f)
+	}
+
+	m.faults = append(m.faults, f)
+	return m
+}
+
+// settle shows failed operations once the user can respond to them,
+// wakes up when new files arrive and ends the session when it only
+// waited for the queue.
+func (m Model) settle(cmd tea.Cmd) (Model, tea.Cmd) {
+	switch {
+	case len(m.faults) > 0 && m.interruptible():
+		m.prev, m.state = m.state, ErrorState
+	case m.state == WaitState && !m.batch.IsComplete():
+		m.state = FileManageState
+		return m, tea.Batch(cmd, m.loadMeta())
+	case m.state == ProcessingState && m.queue.len() == 0:
+		if m.quitting {
+			return m, tea.Quit
+		}
+		m.stats.Finish(time.Now())
+		m.state = EndState
+	}
+
+	return m, cmd
+}
+
+// interruptible reports whether the error screen may replace the
+// current state without losing what the user is doing.
+func (m Model) interruptible() bool {
+	switch m.state {
+	case FileManageState:
+		return !m.querying && !m.marking && !m.naming
+	case ProcessingState, WaitState:
+		return true
+	}
+	return false
+}
+
+// quit exits once queued operations are finished.
+// Failed operations waiting for a decision are given up on.
+func (m Model) quit() (Model, tea.Cmd) {
+	for _, f := range m.faults {
+		m = m.giveUp(f)
+	}
+	m.faults = nil
+
+	if m.queue.len() == 0 {
+		return m, tea.Quit
+	}
+
+	m.quitting = true
+	m.state = ProcessingState
+	return m, nil
+}
+
+// abort cancels running operations and exits once they have stopped.
+// Partial copies are removed and queued files are skipped.
+func (m Model) abort() (Model, tea.Cmd) {
+	m.cancel()
+	if m.state == BulkState {
+		m.quitting = true
+		return m, nil
 	}
+	return m.quit()
 }
 
 func handleProcessingState(m Model, msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC:
-			return m, tea.Quit
-		case tea.KeyRunes:
-			switch msg.String() {
-			case "q":
-				return m, tea.Quit
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+		}
+	}
+
+	return m, nil
+}
+
+// askSimilar asks to apply an action to all files like the current one.
+// Ignored when the current file has no extension or name prefix.
+func (m Model) askSimilar(act action) Model {
+	sims := similarities(m.batch.CurrentFile())
+	if len(sims) == 0 {
+		return m
+	}
+
+	m.state = ConfirmState
+	m.action = act
+	m.sims = sims
+	m.sim = 0
+	return m
+}
+
+// similarFiles returns the current file followed by all pending files
+// sharing the selected trait with it.
+func (m Model) similarFiles() []string {
+	current := m.batch.CurrentFile()
+	match := m.sims[m.sim].match
+
+	files := []string{current}
+	for _, name := range m.batch.PendingFiles() {
+		if name != current && match(name) {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func handleConfirmState(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+		case key.Matches(msg, m.keys.Yes):
+			return m.startBulk(m.action, m.similarFiles())
+		case key.Matches(msg, m.keys.No):
+			m.state = FileManageState
+		case key.Matches(msg, m.keys.Switch):
+			m.sim = (m.sim + 1) % len(m.sims)
+		}
+	}
+
+	return m, nil
+}
+
+// bulkWorkers limits concurrent file operations of a bulk action.
+const bulkWorkers = 4
+
+// startBulk runs an operation on many files in the background.
+func (m Model) startBulk(act action, files []string) (Model, tea.Cmd) {
+	m.state = BulkState
+	m.action = act
+	m.bulkDone = 0
+	m.bulkAll = len(files)
+	m.bulkErrs = nil
+	m.bulk = m.runBulk(act, files)
+
+	return m, waitBulk(m.bulk)
+}
+
+// runBulk processes files with a pool of workers.
+// Results arrive on the returned channel, which closes when all are done.
+func (m Model) runBulk(act action, files []string) <-chan BulkMsg {
+	jobs := make(chan string)
+	results := make(chan BulkMsg)
+
+	var wg sync.WaitGroup
+	for range min(bulkWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range jobs {
+				size, err := m.operate(m.ctx, act, filename, nil)
+				results <- BulkMsg{File: filename, Size: size, Err: err}
 			}
+		}()
+	}
+
+	go func() {
+		for _, filename := range files {
+			jobs <- filename
 		}
-	case ErrorMsg:
-		m.errMsg = msg.Err.Error()
-		m.state = ErrorState
-	case SuccessMsg:
-		m.batch.NextFile()
-		if m.batch.IsComplete() {
-			m.state = EndState
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// waitBulk delivers the next result of a bulk operation.
+func waitBulk(results <-chan BulkMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-results
+		if !ok {
+			return BulkDoneMsg{}
+		}
+		return msg
+	}
+}
+
+// skipMarked skips all marked files at once.
+func (m Model) skipMarked() (Model, tea.Cmd) {
+	for _, filename := range m.batch.MarkedFiles() {
+		m = m.recordFile(filename, domain.StatusSkipped, 0)
+	}
+	m.batch.ClearMarks()
+	return m.resume()
+}
+
+func handleBulkState(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			// Quits once the running bulk operation has finished.
+			m.quitting = true
+			return m, nil
+		}
+	case BulkMsg:
+		m.bulkDone++
+		if errors.Is(msg.Err, context.Canceled) {
+			m = m.recordFile(msg.File, domain.StatusSkipped, 0)
+		} else if msg.Err != nil {
+			m = m.recordFile(msg.File, domain.StatusFailed, 0)
+			f := failedFile{
+				name: msg.File,
+				err:  msg.Err.Error(),
+				kind: errorKind(msg.Err),
+			}
+			m.failed = append(m.failed, f)
+			m.bulkErrs = append(m.bulkErrs, f)
 		} else {
-			m.state = FileManageState
+			m = m.recordFile(msg.File, m.action.status(), msg.Size)
 		}
+		return m, waitBulk(m.bulk)
+	case BulkDoneMsg:
+		m.bulk = nil
+		if m.quitting {
+			return m.quit()
+		}
+		if len(m.bulkErrs) > 0 {
+			m.state = ReportState
+			return m, m.loadSpace()
+		}
+		m, cmd := m.resume()
+		return m, tea.Batch(cmd, m.loadSpace())
+	}
+
+	return m, nil
+}
+
+func handleReportState(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m.abort()
+		}
+		return m.resume()
 	}
 
 	return m, nil
 }
 
+// resume returns to deciding files after acting on many files.
+// Moves on when the current file was decided by the bulk action.
+func (m Model) resume() (Model, tea.Cmd) {
+	if m.batch.CurrentStatus() == domain.StatusPending {
+		m.state = FileManageState
+		return m, m.loadMeta()
+	}
+	return m.nextFile()
+}
+
 func handleEndState(m Model, msg tea.Msg) (Model, tea.Cmd) {
 	switch msg.(type) {
 	case tea.KeyMsg:
@@ -115,11 +775,210 @@ func handleEndState(m Model, msg tea.Msg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+func handleWaitState(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+		}
+	}
+
+	return m, nil
+}
+
 func handleErrorState(m Model, msg tea.Msg) (Model, tea.Cmd) {
-	switch msg.(type) {
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		return m, tea.Quit
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m.abort()
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+		case key.Matches(msg, m.keys.Retry):
+			f := m.faults[0]
+			m.faults = m.faults[1:]
+			m, cmd := m.schedule(f.op)
+			return m.closeError(), cmd
+		case key.Matches(msg, m.keys.Skip):
+			file := m.faults[0].op.file
+			return m.dismiss(func(f fault) bool { return f.op.file == file }), nil
+		case key.Matches(msg, m.keys.Ignore):
+			kind := errorKind(m.faults[0].err)
+			m.ignored[kind] = true
+			return m.dismiss(func(f fault) bool { return errorKind(f.err) == kind }), nil
+		}
 	}
 
 	return m, nil
 }
+
+// dismiss gives up on the failed operations matched by drop.
+func (m Model) dismiss(drop func(fault) bool) Model {
+	var left []fault
+	for _, f := range m.faults {
+		if drop(f) {
+			m = m.giveUp(f)
+		} else {
+			left = append(left, f)
+		}
+	}
+
+	m.faults = left
+	return m.closeError()
+}
+
+// closeError returns to the interrupted state once no failure is left.
+func (m Model) closeError() Model {
+	if len(m.faults) == 0 {
+		m.state = m.prev
+	}
+	return m
+}
+
+// nextFile advances the batch and picks the state for what comes next.
+// Returns a command loading metadata of the new current file. Once all
+// files are decided, settle ends the session when the queue is empty.
+func (m Model) nextFile() (Model, tea.Cmd) {
+	m.batch.NextFile()
+	if m.batch.IsComplete() {
+		m.batch.Filter(nil)
+		m.state = ProcessingState
+		if m.watch != nil {
+			m.state = WaitState
+		}
+		return m, nil
+	}
+
+	m.state = FileManageState
+	return m, m.loadMeta()
+}
+
+// loadMeta reads metadata of the current file in the background and
+// prefetches the file after it. Loaded files are not read again, files
+// that failed to load are.
+func (m Model) loadMeta() tea.Cmd {
+	return tea.Batch(m.fetchMeta(m.batch.CurrentFile()), m.fetchMeta(m.batch.PeekNext()))
+}
+
+func (m Model) fetchMeta(filename string) tea.Cmd {
+	if filename == "" {
+		return nil
+	}
+	if loaded, ok := m.metas[filename]; ok && loaded.Err == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		meta, err := m.manager.Metadata(filename)
+		return MetaMsg{
+			File: filename,
+			Meta: meta,
+			Err:  err,
+		}
+	}
+}
+
+// loadSpace reads the room left in the target directory in the background.
+func (m Model) loadSpace() tea.Cmd {
+	return func() tea.Msg {
+		space, err := m.manager.Space()
+		return SpaceMsg{Space: space, Err: err}
+	}
+}
+
+// waitFiles receives the next new files in watch mode.
+func (m Model) waitFiles() tea.Cmd {
+	if m.watch == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		groups, ok := <-m.watch
+		if !ok {
+			return nil
+		}
+		return FilesMsg{Groups: groups}
+	}
+}
+
+// waitProgress receives the next progress report of a running copy.
+// Started with the first queued operation and kept for the session.
+func (m Model) waitProgress() tea.Cmd {
+	return func() tea.Msg {
+		return <-m.progress
+	}
+}
+
+// giveUp records the file of a failed operation as failed.
+func (m Model) giveUp(f fault) Model {
+	m = m.recordFile(f.op.file, domain.StatusFailed, 0)
+	m.failed = append(m.failed, failedFile{
+		name: f.op.file,
+		err:  f.err.Error(),
+		kind: errorKind(f.err),
+	})
+	return m
+}
+
+// record stores a decision for the current file in batch and stats.
+func (m Model) record(status domain.FileStatus, size int64) Model {
+	return m.recordFile(m.batch.CurrentFile(), status, size)
+}
+
+// recordFile stores a decision for a file in batch and stats.
+// A file decided again no longer counts as skipped or failed. Metadata
+// of files kept or deleted is dropped, as a new file may take the name.
+func (m Model) recordFile(filename string, status domain.FileStatus, size int64) Model {
+	prev := m.batch.StatusOf(filename)
+
+	m.stats.Withdraw(filename, prev)
+	if prev == domain.StatusFailed {
+		m.failed = slices.DeleteFunc(slices.Clone(m.failed), func(f failedFile) bool {
+			return f.name == filename
+		})
+	}
+
+	switch status {
+	case domain.StatusKept:
+		m.stats.RecordKeep(filename, size)
+		delete(m.metas, filename)
+	case domain.StatusDeleted:
+		m.stats.RecordDelete(filename, size)
+		delete(m.metas, filename)
+	case domain.StatusSkipped:
+		m.stats.RecordSkip(filename)
+	case domain.StatusFailed:
+		m.stats.RecordFailure(filename)
+	}
+
+	m.batch.SetStatusOf(filename, status)
+	return m
+}
+
+// errorKind groups errors that differ only in the affected path,
+// so ignoring one permission error ignores all of them.
+func errorKind(err error) string {
+	for _, kind := range []error{domain.ErrNoSpace, domain.ErrQuota} {
+		if errors.Is(err, kind) {
+			return kind.Error()
+		}
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Op + ": " + pathErr.Err.Error()
+	}
+
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Op + ": " + linkErr.Err.Error()
+	}
+
+	return err.Error()
+}
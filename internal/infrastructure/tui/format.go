@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rycln/filer/internal/domain"
+)
+
+// formatBytes renders a byte count with binary unit prefixes.
+func formatBytes(n int64) string {
+	return domain.FormatSize(n)
+}
+
+// formatDuration rounds a duration for display.
+// Sub-second values keep millisecond precision.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// formatTransfer describes a running copy: share done, throughput and
+// estimated time left, e.g. "45% of 1.2 GiB, 30.5 MiB/s, 22s left".
+func formatTransfer(copied, total int64, elapsed time.Duration) string {
+	if total <= 0 || elapsed <= 0 {
+		return formatBytes(copied)
+	}
+
+	rate := float64(copied) / elapsed.Seconds()
+	s := fmt.Sprintf("%d%% of %s, %s/s", copied*100/total, formatBytes(total), formatBytes(int64(rate)))
+	if rate > 0 && copied < total {
+		left := time.Duration(float64(total-copied) / rate * float64(time.Second))
+		s += fmt.Sprintf(", %s left", left.Round(time.Second))
+	}
+	return s
+}
+
+// truncateMiddle shortens s to at most width terminal cells by cutting
+// out its middle, keeping both the start and the extension visible.
+func truncateMiddle(s string, width int, ellipsis string) string {
+	if width <= 0 || runewidth.StringWidth(s) <= width {
+		return s
+	}
+
+	budget := width - runewidth.StringWidth(ellipsis)
+	if budget <= 0 {
+		return runewidth.Truncate(ellipsis, width, "")
+	}
+
+	runes := []rune(s)
+	leftBudget := budget - budget/2
+	rightBudget := budget / 2
+
+	var left []rune
+	used := 0
+	for _, r := range runes {
+		w := runewidth.RuneWidth(r)
+		if used+w > leftBudget {
+			break
+		}
+		left = append(left, r)
+		used += w
+	}
+
+	var right []rune
+	used = 0
+	for i := len(runes) - 1; i >= len(left); i-- {
+		w := runewidth.RuneWidth(runes[i])
+		if used+w > rightBudget {
+			break
+		}
+		right = append([]rune{runes[i]}, right...)
+		used += w
+	}
+
+	return string(left) + ellipsis + string(right)
+}
+
+// formatRelative describes how long ago t was, e.g. "3 months ago".
+func formatRelative(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		return "in the future"
+	}
+
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute") + " ago"
+	case d < day:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	case d < month:
+		return plural(int(d/day), "day") + " ago"
+	case d < year:
+		return plural(int(d/month), "month") + " ago"
+	default:
+		return plural(int(d/year), "year") + " ago"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 << 40, "3.0 TiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.in); got != tt.want {
+			t.Errorf("formatBytes(%d) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := formatDuration(1234567 * time.Microsecond); got != "1.2s" {
+		t.Errorf("Expected 1.2s, got %s", got)
+	}
+	if got := formatDuration(1500 * time.Microsecond); got != "2ms" {
+		t.Errorf("Expected 2ms, got %s", got)
+	}
+}
+
+func TestFormatTransfer(t *testing.T) {
+	tests := []struct {
+		copied, total int64
+		elapsed       time.Duration
+		want          string
+	}{
+		{25 << 20, 100 << 20, 5 * time.Second, "25% of 100.0 MiB, 5.0 MiB/s, 15s left"},
+		{100 << 20, 100 << 20, 20 * time.Second, "100% of 100.0 MiB, 5.0 MiB/s"},
+		{512, 0, time.Second, "512 B"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTransfer(tt.copied, tt.total, tt.elapsed); got != tt.want {
+			t.Errorf("formatTransfer(%d, %d, %s) = %s, want %s", tt.copied, tt.total, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	t.Run("should keep short names unchanged", func(t *testing.T) {
+		if got := truncateMiddle("file.txt", 20, "…"); got != "file.txt" {
+			t.Errorf("Expected unchanged name, got %s", got)
+		}
+	})
+
+	t.Run("should keep start and end of long names", func(t *testing.T) {
+		got := truncateMiddle("a_very_long_file_name.jpg", 11, "…")
+
+		if got != "a_ver…e.jpg" {
+			t.Errorf("Expected 'a_ver…e.jpg', got '%s'", got)
+		}
+	})
+
+	t.Run("should respect wide runes", func(t *testing.T) {
+		name := "写真写真写真写真写真.jpg"
+
+		got := truncateMiddle(name, 10, "...")
+
+		if w := runewidth.StringWidth(got); w > 10 {
+			t.Errorf("Expected at most 10 cells, got %d in '%s'", w, got)
+		}
+		if got[len(got)-3:] != "jpg" {
+			t.Errorf("Expected extension to be kept, got '%s'", got)
+		}
+	})
+
+	t.Run("should not exceed tiny widths", func(t *testing.T) {
+		got := truncateMiddle("filename.txt", 2, "...")
+
+		if runewidth.StringWidth(got) > 2 {
+			t.Errorf("Expected at most 2 cells, got '%s'", got)
+		}
+	})
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{95 * 24 * time.Hour, "3 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+		{-time.Hour, "in the future"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRelative(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("formatRelative(-%v) = %s, want %s", tt.ago, got, tt.want)
+		}
+	}
+}
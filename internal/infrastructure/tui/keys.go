@@ -0,0 +1,281 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the key bindings of all TUI actions.
+// Implements help.KeyMap so help is generated from active bindings.
+type KeyMap struct {
+	Keep      key.Binding
+	Rename    key.Binding
+	Copy      key.Binding
+	Symlink   key.Binding
+	Hardlink  key.Binding
+	Delete    key.Binding
+	Skip      key.Binding
+	KeepAll   key.Binding
+	DeleteAll key.Binding
+	Yes       key.Binding
+	No        key.Binding
+	Switch    key.Binding
+	Retry     key.Binding
+	Ignore    key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Top       key.Binding
+	Bottom    key.Binding
+	List      key.Binding
+	Mark      key.Binding
+	Visual    key.Binding
+	Match     key.Binding
+	Unmark    key.Binding
+	Filter    key.Binding
+	Mode      key.Binding
+	Apply     key.Binding
+	Clear     key.Binding
+	Help      key.Binding
+	Quit      key.Binding
+}
+
+// DefaultKeyMap returns the built-in key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Keep: key.NewBinding(
+			key.WithKeys("k"),
+			key.WithHelp("k", "keep"),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "rename and keep"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy"),
+		),
+		Symlink: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "symlink"),
+		),
+		Hardlink: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "hard link"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete"),
+		),
+		Skip: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "skip"),
+		),
+		KeepAll: key.NewBinding(
+			key.WithKeys("K"),
+			key.WithHelp("K", "keep similar"),
+		),
+		DeleteAll: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "delete similar"),
+		),
+		Yes: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "yes"),
+		),
+		No: key.NewBinding(
+			key.WithKeys("n", "esc"),
+			key.WithHelp("n", "no"),
+		),
+		Switch: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "match by"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry"),
+		),
+		Ignore: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "ignore similar"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("up", "previous file"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down"),
+			key.WithHelp("down", "next file"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("g", "home"),
+			key.WithHelp("g", "first file"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G", "end"),
+			key.WithHelp("G", "last file"),
+		),
+		List: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "toggle file list"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark"),
+		),
+		Visual: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "select range"),
+		),
+		Match: key.NewBinding(
+			key.WithKeys("*"),
+			key.WithHelp("*", "mark matching"),
+		),
+		Unmark: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "clear marks"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Mode: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "regex/fuzzy"),
+		),
+		Apply: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply filter"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear filter"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// NewKeyMap applies user bindings on top of the defaults.
+// Bindings map action names to keys, e.g. "keep": ["y"].
+// Returns error for unknown actions or keys bound twice in one screen.
+func NewKeyMap(bindings map[string][]string) (KeyMap, error) {
+	km := DefaultKeyMap()
+	actions := km.actions()
+
+	for name, keys := range bindings {
+		b, ok := actions[name]
+		if !ok {
+			return KeyMap{}, fmt.Errorf("unknown key action: %s", name)
+		}
+		if len(keys) == 0 {
+			return KeyMap{}, fmt.Errorf("no keys bound to action: %s", name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	screens := [][]key.Binding{
+		km.manageBindings(), km.errorBindings(), km.filterBindings(), km.confirmBindings(),
+	}
+	for _, screen := range screens {
+		if err := checkConflicts(screen); err != nil {
+			return KeyMap{}, err
+		}
+	}
+
+	return km, nil
+}
+
+// ShortHelp returns the bindings shown in the manage screen options line.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Keep, k.Delete, k.Skip, k.Quit, k.Help}
+}
+
+// FullHelp returns all bindings grouped for the help overlay.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Keep, k.Rename, k.Copy, k.Symlink, k.Hardlink, k.Delete, k.Skip, k.KeepAll, k.DeleteAll},
+		{k.Up, k.Down, k.Top, k.Bottom, k.List},
+		{k.Mark, k.Visual, k.Match, k.Unmark},
+		{k.Filter, k.Mode, k.Apply, k.Clear},
+		{k.Yes, k.No, k.Switch},
+		{k.Retry, k.Ignore},
+		{k.Help, k.Quit},
+	}
+}
+
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"keep":           &k.Keep,
+		"rename":         &k.Rename,
+		"copy":           &k.Copy,
+		"symlink":        &k.Symlink,
+		"hardlink":       &k.Hardlink,
+		"delete":         &k.Delete,
+		"skip":           &k.Skip,
+		"keep-similar":   &k.KeepAll,
+		"delete-similar": &k.DeleteAll,
+		"yes":            &k.Yes,
+		"no":             &k.No,
+		"switch":         &k.Switch,
+		"retry":          &k.Retry,
+		"ignore":         &k.Ignore,
+		"up":             &k.Up,
+		"down":           &k.Down,
+		"top":            &k.Top,
+		"bottom":         &k.Bottom,
+		"list":           &k.List,
+		"mark":           &k.Mark,
+		"visual":         &k.Visual,
+		"match":          &k.Match,
+		"unmark":         &k.Unmark,
+		"filter":         &k.Filter,
+		"mode":           &k.Mode,
+		"apply":          &k.Apply,
+		"clear":          &k.Clear,
+		"help":           &k.Help,
+		"quit":           &k.Quit,
+	}
+}
+
+func (k KeyMap) manageBindings() []key.Binding {
+	return []key.Binding{
+		k.Keep, k.Rename, k.Copy, k.Symlink, k.Hardlink, k.Delete, k.Skip, k.KeepAll, k.DeleteAll, k.Up, k.Down, k.Top, k.Bottom, k.List,
+		k.Mark, k.Visual, k.Match, k.Unmark, k.Filter, k.Clear, k.Help, k.Quit,
+	}
+}
+
+// filterBindings are active while a text input has focus.
+// Other keys are typed into the query.
+func (k KeyMap) filterBindings() []key.Binding {
+	return []key.Binding{k.Mode, k.Apply, k.Clear}
+}
+
+func (k KeyMap) confirmBindings() []key.Binding {
+	return []key.Binding{k.Yes, k.No, k.Switch, k.Quit}
+}
+
+func (k KeyMap) errorBindings() []key.Binding {
+	return []key.Binding{k.Retry, k.Skip, k.Ignore, k.Help, k.Quit}
+}
+
+func checkConflicts(bindings []key.Binding) error {
+	seen := make(map[string]string)
+	for _, b := range bindings {
+		for _, k := range b.Keys() {
+			if other, ok := seen[k]; ok {
+				return fmt.Errorf("key %q is bound to both %s and %s", k, other, b.Help().Desc)
+			}
+			seen[k] = b.Help().Desc
+		}
+	}
+	return nil
+}
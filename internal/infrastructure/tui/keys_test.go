@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/golang/mock/gomock"
+	"github.com/rycln/filer/internal/domain"
+	"github.com/rycln/filer/internal/infrastructure/tui/mocks"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	t.Run("should return defaults without bindings", func(t *testing.T) {
+		keys, err := NewKeyMap(nil)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if keys.Keep.Help().Key != "k" {
+			t.Errorf("Expected default keep key 'k', got '%s'", keys.Keep.Help().Key)
+		}
+	})
+
+	t.Run("should override bindings for known actions", func(t *testing.T) {
+		keys, err := NewKeyMap(map[string][]string{
+			"keep": {"y", "enter"},
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := keys.Keep.Keys(); len(got) != 2 || got[0] != "y" || got[1] != "enter" {
+			t.Errorf("Expected keep keys [y enter], got %v", got)
+		}
+		if keys.Keep.Help().Key != "y/enter" {
+			t.Errorf("Expected help key 'y/enter', got '%s'", keys.Keep.Help().Key)
+		}
+	})
+
+	t.Run("should return error for unknown action", func(t *testing.T) {
+		_, err := NewKeyMap(map[string][]string{
+			"explode": {"x"},
+		})
+
+		if err == nil {
+			t.Error("Expected error for unknown action")
+		}
+	})
+
+	t.Run("should return error for conflicting keys", func(t *testing.T) {
+		_, err := NewKeyMap(map[string][]string{
+			"keep": {"d"},
+		})
+
+		if err == nil {
+			t.Error("Expected error for key bound twice")
+		}
+	})
+
+	t.Run("should allow same key on different screens", func(t *testing.T) {
+		_, err := NewKeyMap(map[string][]string{
+			"keep":  {"x"},
+			"retry": {"x"},
+		})
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestModel_KeyMap(t *testing.T) {
+	t.Run("should use custom bindings in file manage state", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		keys, err := NewKeyMap(map[string][]string{"keep": {"y"}})
+		if err != nil {
+			t.Fatalf("Failed to create key map: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithKeyMap(keys))
+
+		updatedTeaModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+		if updatedTeaModel.(Model).state != FileManageState {
+			t.Error("Old keep key should no longer be bound")
+		}
+
+		updatedTeaModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if updatedTeaModel.(Model).state != ProcessingState {
+			t.Errorf("Expected ProcessingState, got %v", updatedTeaModel.(Model).state)
+		}
+		if cmd == nil {
+			t.Error("Expected keep command")
+		}
+	})
+
+	t.Run("should toggle help overlay on '?' key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}}
+		updatedTeaModel, _ := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
+
+		if !updatedModel.showHelp {
+			t.Fatal("Expected help overlay to be shown")
+		}
+		if !strings.Contains(updatedModel.View(), "ignore similar") {
+			t.Error("Help overlay should list all bindings")
+		}
+
+		updatedTeaModel, _ = updatedModel.Update(msg)
+		if updatedTeaModel.(Model).showHelp {
+			t.Error("Expected help overlay to be hidden")
+		}
+	})
+
+	t.Run("should generate options line from bindings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		keys, err := NewKeyMap(map[string][]string{"keep": {"y"}})
+		if err != nil {
+			t.Fatalf("Failed to create key map: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithKeyMap(keys))
+
+		view := model.View()
+
+		if !strings.Contains(view, "y Keep") {
+			t.Error("Options line should show the custom keep key")
+		}
+		if !strings.Contains(view, "Delete") {
+			t.Error("Options line should show default bindings")
+		}
+	})
+}
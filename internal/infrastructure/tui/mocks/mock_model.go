@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/rycln/filer/internal/domain"
 )
 
 // MockFileManager is a mock of FileManager interface.
@@ -33,6 +35,20 @@ func (m *MockFileManager) EXPECT() *MockFileManagerMockRecorder {
 	return m.recorder
 }
 
+// Copy mocks base method.
+func (m *MockFileManager) Copy(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockFileManagerMockRecorder) Copy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockFileManager)(nil).Copy), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockFileManager) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -47,16 +63,103 @@ func (mr *MockFileManagerMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFileManager)(nil).Delete), arg0)
 }
 
+// Hardlink mocks base method.
+func (m *MockFileManager) Hardlink(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hardlink", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Hardlink indicates an expected call of Hardlink.
+func (mr *MockFileManagerMockRecorder) Hardlink(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hardlink", reflect.TypeOf((*MockFileManager)(nil).Hardlink), arg0)
+}
+
 // Keep mocks base method.
-func (m *MockFileManager) Keep(arg0 string) error {
+func (m *MockFileManager) Keep(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Keep", arg0)
+	ret := m.ctrl.Call(m, "Keep", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Keep indicates an expected call of Keep.
-func (mr *MockFileManagerMockRecorder) Keep(arg0 interface{}) *gomock.Call {
+func (mr *MockFileManagerMockRecorder) Keep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keep", reflect.TypeOf((*MockFileManager)(nil).Keep), arg0, arg1)
+}
+
+// KeepAs mocks base method.
+func (m *MockFileManager) KeepAs(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeepAs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// KeepAs indicates an expected call of KeepAs.
+func (mr *MockFileManagerMockRecorder) KeepAs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepAs", reflect.TypeOf((*MockFileManager)(nil).KeepAs), arg0, arg1, arg2)
+}
+
+// Metadata mocks base method.
+func (m *MockFileManager) Metadata(arg0 string) (domain.FileMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metadata", arg0)
+	ret0, _ := ret[0].(domain.FileMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata.
+func (mr *MockFileManagerMockRecorder) Metadata(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockFileManager)(nil).Metadata), arg0)
+}
+
+// Size mocks base method.
+func (m *MockFileManager) Size(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Size", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Size indicates an expected call of Size.
+func (mr *MockFileManagerMockRecorder) Size(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockFileManager)(nil).Size), arg0)
+}
+
+// Space mocks base method.
+func (m *MockFileManager) Space() (domain.TargetSpace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Space")
+	ret0, _ := ret[0].(domain.TargetSpace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Space indicates an expected call of Space.
+func (mr *MockFileManagerMockRecorder) Space() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Space", reflect.TypeOf((*MockFileManager)(nil).Space))
+}
+
+// Symlink mocks base method.
+func (m *MockFileManager) Symlink(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Symlink", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Symlink indicates an expected call of Symlink.
+func (mr *MockFileManagerMockRecorder) Symlink(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keep", reflect.TypeOf((*MockFileManager)(nil).Keep), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Symlink", reflect.TypeOf((*MockFileManager)(nil).Symlink), arg0)
 }
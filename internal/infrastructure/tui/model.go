@@ -1,6 +1,16 @@
 package tui
 
-import "github.com/rycln/filer/internal/domain"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/rycln/filer/internal/domain"
+)
 
 //go:generate mockgen -source=$GOFILE -destination=./mocks/mock_$GOFILE -package=mocks
 
@@ -10,41 +20,334 @@ type state int
 
 const (
 	FileManageState state = iota // Main file management interface
-	ProcessingState              // File operation in progress
+	ProcessingState              // All files decided, finishing queued operations
 	EndState                     // Processing completed
 	ErrorState                   // Error display state
+	BulkState                    // Operation on marked files in progress
+	ReportState                  // Errors of a finished bulk operation
+	ConfirmState                 // Asking to decide all similar files
+	WaitState                    // All files decided, watching for new ones
 )
 
-// SuccessMsg indicates successful file operation.
-// Used for state transitions after keep/delete.
-type SuccessMsg struct{}
+// SuccessMsg indicates a finished file operation.
+// Records the decision for the file.
+type SuccessMsg struct {
+	File string
+	Size int64
+}
 
 // ErrorMsg wraps file operation errors.
 // Carries error details for error state.
-type ErrorMsg struct{ Err error }
+type ErrorMsg struct {
+	File string
+	Err  error
+}
+
+// ProgressMsg reports bytes copied so far by a running operation.
+type ProgressMsg struct {
+	File   string
+	Copied int64
+	Total  int64
+}
+
+// TickMsg refreshes the status line of queued operations.
+type TickMsg struct{}
+
+// MetaMsg delivers metadata loaded for a file in the background.
+type MetaMsg struct {
+	File string
+	Meta domain.FileMeta
+	Err  error
+}
+
+// SpaceMsg delivers the room left in the target directory.
+type SpaceMsg struct {
+	Space domain.TargetSpace
+	Err   error
+}
+
+// BulkMsg reports one file finished by a bulk operation.
+type BulkMsg struct {
+	File string
+	Size int64
+	Err  error
+}
+
+// FilesMsg delivers groups of new files found by the watcher.
+type FilesMsg struct{ Groups [][]string }
+
+// BulkDoneMsg signals that all files of a bulk operation are finished.
+type BulkDoneMsg struct{}
+
+// action identifies the file operation started from the manage state.
+// Remembered so a failed operation can be retried.
+type action int
+
+const (
+	keepAction action = iota
+	deleteAction
+	copyAction
+	renameAction
+	symlinkAction
+	hardlinkAction
+)
+
+// keepModes maps --keep-mode values to the action run by the keep key.
+var keepModes = map[string]action{
+	"move":     keepAction,
+	"copy":     copyAction,
+	"symlink":  symlinkAction,
+	"hardlink": hardlinkAction,
+}
+
+// String returns the action name as shown in titles.
+func (a action) String() string {
+	switch a {
+	case deleteAction:
+		return "Delete"
+	case copyAction:
+		return "Copy"
+	case renameAction:
+		return "Rename"
+	case symlinkAction:
+		return "Symlink"
+	case hardlinkAction:
+		return "Hard link"
+	default:
+		return "Keep"
+	}
+}
+
+// progressive returns the action name for operations in progress.
+func (a action) progressive() string {
+	switch a {
+	case deleteAction:
+		return "Deleting"
+	case copyAction:
+		return "Copying"
+	case renameAction:
+		return "Renaming"
+	case symlinkAction, hardlinkAction:
+		return "Linking"
+	default:
+		return "Keeping"
+	}
+}
+
+// status returns the decision recorded when the action succeeds.
+func (a action) status() domain.FileStatus {
+	if a == deleteAction {
+		return domain.StatusDeleted
+	}
+	return domain.StatusKept
+}
+
+// fault is a queued operation that failed and waits for the user to
+// retry or give up on it.
+type fault struct {
+	op  operation
+	err error
+}
+
+// failedFile records a file that was given up on after an error.
+// Listed on the completion screen.
+type failedFile struct {
+	name string
+	err  string
+	kind string
+}
 
 // FileManager defines file operations for TUI.
 // Abstraction for keep/delete business logic.
 type FileManager interface {
-	Keep(string) error
+	Keep(context.Context, string) error
+	KeepAs(context.Context, string, string) error
+	Copy(context.Context, string) error
+	Symlink(string) error
+	Hardlink(string) error
 	Delete(string) error
+	Size(string) (int64, error)
+	Metadata(string) (domain.FileMeta, error)
+	Space() (domain.TargetSpace, error)
 }
 
 // Model represents TUI application state.
 // Manages UI state, file batch and business logic.
 type Model struct {
-	state   state
-	errMsg  string
-	batch   *domain.FileBatch
-	manager FileManager
+	state     state
+	prev      state // state interrupted by the error screen
+	action    action
+	keepAs    action
+	failed    []failedFile
+	ignored   map[string]bool
+	stats     *domain.SessionStats
+	keys      KeyMap
+	theme     Theme
+	plain     bool
+	styles    styles
+	glyphs    glyphs
+	help      help.Model
+	showHelp  bool
+	showList  bool
+	query     textinput.Model
+	querying  bool
+	regex     bool
+	queryErr  error
+	pattern   textinput.Model
+	marking   bool
+	matchErr  error
+	name      textinput.Model
+	naming    bool
+	nameErr   error
+	newName   string
+	renames   int
+	visual    bool
+	anchor    int
+	sims      []similarity
+	sim       int
+	bulk      <-chan BulkMsg
+	bulkDone  int
+	bulkAll   int
+	bulkErrs  []failedFile
+	watch     <-chan [][]string
+	queue     *opQueue
+	faults    []fault
+	progress  chan ProgressMsg
+	copies    map[string]ProgressMsg
+	ctx       context.Context
+	cancel    context.CancelFunc // aborts running operations on ctrl+c
+	ticking   bool
+	listening bool // receiving progress of running copies
+	quitting  bool
+	width     int
+	height    int
+	metas     map[string]MetaMsg
+	space     domain.TargetSpace // room left in the target directory
+	target    bool               // kept files move to a target directory
+	batch     *domain.FileBatch
+	manager   FileManager
+}
+
+// Option customizes the model created by InitialModel.
+type Option func(*Model)
+
+// WithKeyMap replaces the default key bindings.
+func WithKeyMap(keys KeyMap) Option {
+	return func(m *Model) {
+		m.keys = keys
+	}
+}
+
+// WithTheme replaces the default colour theme.
+func WithTheme(theme Theme) Option {
+	return func(m *Model) {
+		m.theme = theme
+	}
+}
+
+// WithKeepMode sets what the keep key does: move, copy, symlink or
+// hardlink. Unknown modes keep the default move.
+func WithKeepMode(mode string) Option {
+	return func(m *Model) {
+		if act, ok := keepModes[mode]; ok {
+			m.keepAs = act
+		}
+	}
+}
+
+// WithTarget tells the model that kept files move to a target directory.
+// Without it files are kept in place and no bytes count as moved.
+func WithTarget() Option {
+	return func(m *Model) {
+		m.target = true
+	}
+}
+
+// WithWatch appends new files received from files to the batch and
+// waits for more instead of ending when all files are decided.
+func WithWatch(files <-chan [][]string) Option {
+	return func(m *Model) {
+		m.watch = files
+	}
+}
+
+// WithList shows the file list pane from the start.
+func WithList() Option {
+	return func(m *Model) {
+		m.showList = true
+	}
+}
+
+// WithPlain renders line-oriented text without colours or emoji.
+// Meant for limited terminals and screen readers.
+func WithPlain() Option {
+	return func(m *Model) {
+		m.plain = true
+	}
 }
 
 // InitialModel creates TUI model with file batch.
 // Starts in FileManageState for user interaction.
-func InitialModel(batch *domain.FileBatch, manager FileManager) Model {
-	return Model{
-		state:   FileManageState,
-		batch:   batch,
-		manager: manager,
+func InitialModel(batch *domain.FileBatch, manager FileManager, opts ...Option) Model {
+	m := Model{
+		state:    FileManageState,
+		ignored:  make(map[string]bool),
+		metas:    make(map[string]MetaMsg),
+		queue:    newOpQueue(queueWorkers),
+		progress: make(chan ProgressMsg, progressBuffer),
+		copies:   make(map[string]ProgressMsg),
+		space:    domain.TargetSpace{Free: -1, Quota: -1},
+		stats:    domain.NewSessionStats(time.Now()),
+		keys:     DefaultKeyMap(),
+		theme:    builtinThemes[DefaultTheme],
+		help:     help.New(),
+		query:    textinput.New(),
+		pattern:  textinput.New(),
+		name:     textinput.New(),
+		batch:    batch,
+		manager:  manager,
 	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	if m.watch != nil && m.batch.IsComplete() {
+		m.state = WaitState
+	}
+
+	if m.keepAs != keepAction {
+		help := m.keys.Keep.Help()
+		m.keys.Keep.SetHelp(help.Key, fmt.Sprintf("%s (%s)", help.Desc, strings.ToLower(m.keepAs.String())))
+	}
+
+	if m.plain {
+		m.styles = plainStyles()
+		m.glyphs = plainGlyphs
+		m.help.Styles = help.Styles{}
+		m.query.Cursor.SetMode(cursor.CursorHide)
+		m.pattern.Cursor.SetMode(cursor.CursorHide)
+		m.name.Cursor.SetMode(cursor.CursorHide)
+	} else {
+		m.styles = newStyles(m.theme)
+		m.glyphs = emojiGlyphs
+		m.help.Styles = newHelpStyles(m.theme)
+	}
+
+	for _, input := range []*textinput.Model{&m.query, &m.pattern, &m.name} {
+		input.Prompt = ""
+		input.TextStyle = m.styles.text
+		input.PlaceholderStyle = m.styles.label
+	}
+	m.query.Placeholder = "type to filter"
+	m.pattern.Placeholder = "files to mark"
+
+	return m
+}
+
+// Stats returns the statistics of the session.
+func (m Model) Stats() *domain.SessionStats {
+	return m.stats
 }
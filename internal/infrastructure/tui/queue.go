@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// queueWorkers limits file operations running at the same time.
+const queueWorkers = 2
+
+// tickInterval is how often the status line of running operations
+// is refreshed.
+const tickInterval = time.Second
+
+// progressInterval is how often a running copy reports its progress.
+const progressInterval = 200 * time.Millisecond
+
+// progressBuffer is the number of progress reports waiting for the
+// view. Reports beyond it are dropped; the next one catches up.
+const progressBuffer = 16
+
+// operation is a file operation decided by the user and run in the
+// background while they move on.
+type operation struct {
+	file    string
+	act     action
+	run     tea.Cmd   // runs the operation, again when retried
+	started time.Time // zero while waiting for a worker
+}
+
+// opQueue tracks queued and running operations.
+// Workers update it while the view reads it, so access is locked.
+type opQueue struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	ops   []operation
+}
+
+func newOpQueue(workers int) *opQueue {
+	return &opQueue{slots: make(chan struct{}, workers)}
+}
+
+// add queues op as not started yet.
+func (q *opQueue) add(op operation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	op.started = time.Time{}
+	q.ops = append(q.ops, op)
+}
+
+// acquire waits for a free worker and marks the operation on file running.
+// Fails without taking a worker once ctx is cancelled.
+func (q *opQueue) acquire(ctx context.Context, file string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if i := q.index(file); i >= 0 {
+		q.ops[i].started = time.Now()
+	}
+	return nil
+}
+
+// release frees the worker taken by acquire.
+func (q *opQueue) release() {
+	<-q.slots
+}
+
+// remove drops the finished operation on file and returns it.
+func (q *opQueue) remove(file string) operation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.index(file)
+	if i < 0 {
+		return operation{file: file}
+	}
+	op := q.ops[i]
+	q.ops = slices.Delete(q.ops, i, i+1)
+	return op
+}
+
+// snapshot returns the queued and running operations in queue order.
+func (q *opQueue) snapshot() []operation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return slices.Clone(q.ops)
+}
+
+// running reports whether the operation on file has taken a worker.
+func (q *opQueue) running(file string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.index(file)
+	return i >= 0 && !q.ops[i].started.IsZero()
+}
+
+// len returns the number of unfinished operations.
+func (q *opQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.ops)
+}
+
+func (q *opQueue) index(file string) int {
+	return slices.IndexFunc(q.ops, func(op operation) bool { return op.file == file })
+}
+
+// reporter returns a progress callback for file that sends at most one
+// report per progressInterval to progress without blocking the copy.
+func reporter(progress chan<- ProgressMsg, file string, total int64) func(int64) {
+	var last time.Time
+	return func(copied int64) {
+		if time.Since(last) < progressInterval && copied < total {
+			return
+		}
+		last = time.Now()
+
+		select {
+		case progress <- ProgressMsg{File: file, Copied: copied, Total: total}:
+		default:
+		}
+	}
+}
+
+// tick refreshes the view while operations are running.
+func tick() tea.Cmd {
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg {
+		return TickMsg{}
+	})
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpQueue(t *testing.T) {
+	t.Run("should limit running operations to the workers", func(t *testing.T) {
+		q := newOpQueue(1)
+		q.add(operation{file: "file1.txt"})
+		q.add(operation{file: "file2.txt"})
+
+		q.acquire(context.Background(), "file1.txt")
+		started := make(chan struct{})
+		go func() {
+			q.acquire(context.Background(), "file2.txt")
+			close(started)
+		}()
+
+		select {
+		case <-started:
+			t.Fatal("Second operation should wait for a free worker")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		ops := q.snapshot()
+		if ops[0].started.IsZero() || !ops[1].started.IsZero() {
+			t.Errorf("Expected only file1.txt running, got %v", ops)
+		}
+
+		q.release()
+		<-started
+		q.release()
+	})
+
+	t.Run("should stop waiting for a worker when cancelled", func(t *testing.T) {
+		q := newOpQueue(1)
+		q.add(operation{file: "file1.txt"})
+		q.add(operation{file: "file2.txt"})
+		q.acquire(context.Background(), "file1.txt")
+		defer q.release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := q.acquire(ctx, "file2.txt"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if q.running("file2.txt") {
+			t.Error("Cancelled operation should not be running")
+		}
+	})
+
+	t.Run("should remove finished operations", func(t *testing.T) {
+		q := newOpQueue(1)
+		q.add(operation{file: "file1.txt", act: deleteAction})
+		q.add(operation{file: "file2.txt"})
+
+		op := q.remove("file1.txt")
+
+		if op.act != deleteAction {
+			t.Errorf("Expected the removed delete, got %v", op.act)
+		}
+		if q.len() != 1 || q.snapshot()[0].file != "file2.txt" {
+			t.Errorf("Expected file2.txt left, got %v", q.snapshot())
+		}
+	})
+}
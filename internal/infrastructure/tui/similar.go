@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// similarity describes files sharing a trait with a reference file.
+// Offered when applying a decision to all similar files.
+type similarity struct {
+	label string
+	match func(string) bool
+}
+
+// similarities returns the traits of filename other files can share:
+// its extension and its name prefix, when it has them.
+func similarities(filename string) []similarity {
+	var sims []similarity
+
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" {
+		sims = append(sims, similarity{
+			label: "extension " + ext,
+			match: func(name string) bool {
+				return strings.ToLower(filepath.Ext(name)) == ext
+			},
+		})
+	}
+
+	if prefix := namePrefix(filename); prefix != "" {
+		sims = append(sims, similarity{
+			label: "prefix " + prefix,
+			match: func(name string) bool {
+				return strings.HasPrefix(name, prefix)
+			},
+		})
+	}
+
+	return sims
+}
+
+// namePrefix returns the leading part of a name that cameras and tools
+// put before a counter, such as "IMG_" or "DSC". It ends after the first
+// separator or before the first digit. Empty if there is none.
+func namePrefix(filename string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	for i, r := range base {
+		switch {
+		case unicode.IsDigit(r):
+			return base[:i]
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			if i == 0 {
+				return ""
+			}
+			return base[:i+1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,47 @@
+package tui
+
+import "testing"
+
+func TestNamePrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"IMG_1234.jpg", "IMG_"},
+		{"DSC01234.JPG", "DSC"},
+		{"backup-2024-01-01.tar.gz", "backup-"},
+		{"2024-01-01.log", ""},
+		{"_hidden.txt", ""},
+		{"README", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("should find prefix of "+tt.name, func(t *testing.T) {
+			if got := namePrefix(tt.name); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSimilarities(t *testing.T) {
+	t.Run("should match extension case-insensitively and prefix", func(t *testing.T) {
+		sims := similarities("IMG_1.JPG")
+		if len(sims) != 2 {
+			t.Fatalf("Expected 2 similarities, got %d", len(sims))
+		}
+
+		if sims[0].label != "extension .jpg" || !sims[0].match("a.jpg") || sims[0].match("a.png") {
+			t.Errorf("Unexpected extension similarity %q", sims[0].label)
+		}
+		if sims[1].label != "prefix IMG_" || !sims[1].match("IMG_2.png") || sims[1].match("img_2.png") {
+			t.Errorf("Unexpected prefix similarity %q", sims[1].label)
+		}
+	})
+
+	t.Run("should return nothing for plain names", func(t *testing.T) {
+		if sims := similarities("README"); len(sims) != 0 {
+			t.Errorf("Expected no similarities, got %d", len(sims))
+		}
+	})
+}
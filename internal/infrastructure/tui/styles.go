@@ -1,42 +1,396 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"slices"
+	"strings"
 
-var (
-	titleStyle = lipgloss.NewStyle().
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rycln/filer/internal/domain"
+)
+
+// DefaultTheme follows the detected terminal background.
+const DefaultTheme = "auto"
+
+// Theme assigns colours to the roles used across the views.
+type Theme struct {
+	Title      lipgloss.TerminalColor
+	Progress   lipgloss.TerminalColor
+	File       lipgloss.TerminalColor
+	Option     lipgloss.TerminalColor
+	Success    lipgloss.TerminalColor
+	Error      lipgloss.TerminalColor
+	ErrorText  lipgloss.TerminalColor
+	Processing lipgloss.TerminalColor
+	Divider    lipgloss.TerminalColor
+	BarFilled  lipgloss.TerminalColor
+	BarEmpty   lipgloss.TerminalColor
+	Text       lipgloss.TerminalColor
+}
+
+var builtinThemes = map[string]Theme{
+	"auto": {
+		Title:      lipgloss.AdaptiveColor{Light: "55", Dark: "62"},
+		Progress:   lipgloss.AdaptiveColor{Light: "25", Dark: "39"},
+		File:       lipgloss.AdaptiveColor{Light: "28", Dark: "156"},
+		Option:     lipgloss.AdaptiveColor{Light: "130", Dark: "214"},
+		Success:    lipgloss.AdaptiveColor{Light: "28", Dark: "46"},
+		Error:      lipgloss.AdaptiveColor{Light: "160", Dark: "196"},
+		ErrorText:  lipgloss.AdaptiveColor{Light: "124", Dark: "203"},
+		Processing: lipgloss.AdaptiveColor{Light: "136", Dark: "226"},
+		Divider:    lipgloss.AdaptiveColor{Light: "248", Dark: "240"},
+		BarFilled:  lipgloss.AdaptiveColor{Light: "28", Dark: "46"},
+		BarEmpty:   lipgloss.AdaptiveColor{Light: "250", Dark: "240"},
+		Text:       lipgloss.AdaptiveColor{Light: "236", Dark: "252"},
+	},
+	"dark": {
+		Title:      lipgloss.Color("62"),
+		Progress:   lipgloss.Color("39"),
+		File:       lipgloss.Color("156"),
+		Option:     lipgloss.Color("214"),
+		Success:    lipgloss.Color("46"),
+		Error:      lipgloss.Color("196"),
+		ErrorText:  lipgloss.Color("203"),
+		Processing: lipgloss.Color("226"),
+		Divider:    lipgloss.Color("240"),
+		BarFilled:  lipgloss.Color("46"),
+		BarEmpty:   lipgloss.Color("240"),
+		Text:       lipgloss.Color("252"),
+	},
+	"light": {
+		Title:      lipgloss.Color("55"),
+		Progress:   lipgloss.Color("25"),
+		File:       lipgloss.Color("28"),
+		Option:     lipgloss.Color("130"),
+		Success:    lipgloss.Color("28"),
+		Error:      lipgloss.Color("160"),
+		ErrorText:  lipgloss.Color("124"),
+		Processing: lipgloss.Color("136"),
+		Divider:    lipgloss.Color("248"),
+		BarFilled:  lipgloss.Color("28"),
+		BarEmpty:   lipgloss.Color("250"),
+		Text:       lipgloss.Color("236"),
+	},
+	"high-contrast": {
+		Title:      lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+		Progress:   lipgloss.AdaptiveColor{Light: "4", Dark: "14"},
+		File:       lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+		Option:     lipgloss.AdaptiveColor{Light: "4", Dark: "11"},
+		Success:    lipgloss.AdaptiveColor{Light: "2", Dark: "10"},
+		Error:      lipgloss.AdaptiveColor{Light: "1", Dark: "9"},
+		ErrorText:  lipgloss.AdaptiveColor{Light: "1", Dark: "9"},
+		Processing: lipgloss.AdaptiveColor{Light: "4", Dark: "11"},
+		Divider:    lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+		BarFilled:  lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+		BarEmpty:   lipgloss.AdaptiveColor{Light: "8", Dark: "7"},
+		Text:       lipgloss.AdaptiveColor{Light: "0", Dark: "15"},
+	},
+	"monochrome": {
+		Title:      lipgloss.NoColor{},
+		Progress:   lipgloss.NoColor{},
+		File:       lipgloss.NoColor{},
+		Option:     lipgloss.NoColor{},
+		Success:    lipgloss.NoColor{},
+		Error:      lipgloss.NoColor{},
+		ErrorText:  lipgloss.NoColor{},
+		Processing: lipgloss.NoColor{},
+		Divider:    lipgloss.NoColor{},
+		BarFilled:  lipgloss.NoColor{},
+		BarEmpty:   lipgloss.NoColor{},
+		Text:       lipgloss.NoColor{},
+	},
+}
+
+// ThemeNames returns the names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewTheme looks up a theme by name among user and built-in themes.
+// A user theme overrides roles of its "base" theme (default: auto);
+// a colour given as "light,dark" adapts to the terminal background.
+func NewTheme(name string, custom map[string]map[string]string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	if roles, ok := custom[name]; ok {
+		return customTheme(name, roles)
+	}
+
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %s (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	return theme, nil
+}
+
+func customTheme(name string, roles map[string]string) (Theme, error) {
+	base := DefaultTheme
+	if b, ok := roles["base"]; ok {
+		base = b
+	}
+
+	theme, ok := builtinThemes[base]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme: %s", name, base)
+	}
+
+	fields := theme.roles()
+	for role, value := range roles {
+		if role == "base" {
+			continue
+		}
+		field, ok := fields[role]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %s: unknown colour role: %s", name, role)
+		}
+		*field = parseColor(value)
+	}
+
+	return theme, nil
+}
+
+func (t *Theme) roles() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"title":      &t.Title,
+		"progress":   &t.Progress,
+		"file":       &t.File,
+		"option":     &t.Option,
+		"success":    &t.Success,
+		"error":      &t.Error,
+		"error_text": &t.ErrorText,
+		"processing": &t.Processing,
+		"divider":    &t.Divider,
+		"bar_filled": &t.BarFilled,
+		"bar_empty":  &t.BarEmpty,
+		"text":       &t.Text,
+	}
+}
+
+func parseColor(value string) lipgloss.TerminalColor {
+	if value == "" || value == "none" {
+		return lipgloss.NoColor{}
+	}
+	if light, dark, ok := strings.Cut(value, ","); ok {
+		return lipgloss.AdaptiveColor{
+			Light: strings.TrimSpace(light),
+			Dark:  strings.TrimSpace(dark),
+		}
+	}
+	return lipgloss.Color(value)
+}
+
+// styles holds the lipgloss styles rendered with the active theme.
+type styles struct {
+	title      lipgloss.Style
+	progress   lipgloss.Style
+	file       lipgloss.Style
+	option     lipgloss.Style
+	success    lipgloss.Style
+	err        lipgloss.Style
+	errText    lipgloss.Style
+	processing lipgloss.Style
+	divider    lipgloss.Style
+	barFilled  lipgloss.Style
+	barEmpty   lipgloss.Style
+	text       lipgloss.Style
+	label      lipgloss.Style
+	panel      lipgloss.Style
+	cursor     lipgloss.Style
+	statuses   map[domain.FileStatus]lipgloss.Style
+}
+
+func newStyles(t Theme) styles {
+	return styles{
+		title: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("62")).
-			PaddingBottom(1)
+			Foreground(t.Title).
+			PaddingBottom(1),
 
-	progressStyle = lipgloss.NewStyle().
+		progress: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("39")).
-			PaddingBottom(1)
+			Foreground(t.Progress).
+			PaddingBottom(1),
 
-	fileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("156")).
+		file: lipgloss.NewStyle().
+			Foreground(t.File).
 			Italic(true).
-			PaddingBottom(1)
+			PaddingBottom(1),
 
-	optionStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("214")).
-			Bold(true)
+		option: lipgloss.NewStyle().
+			Foreground(t.Option).
+			Bold(true),
 
-	successStyle = lipgloss.NewStyle().
+		success: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("46")).
-			PaddingBottom(1)
+			Foreground(t.Success).
+			PaddingBottom(1),
 
-	errorStyle = lipgloss.NewStyle().
+		err: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("196")).
-			PaddingBottom(1)
+			Foreground(t.Error).
+			PaddingBottom(1),
 
-	processingStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")).
-			Bold(true)
+		errText: lipgloss.NewStyle().
+			Foreground(t.ErrorText).
+			BorderLeft(true).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(t.Error).
+			PaddingLeft(1),
 
-	dividerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			SetString("┃")
-)
+		processing: lipgloss.NewStyle().
+			Foreground(t.Processing).
+			Bold(true),
+
+		divider: lipgloss.NewStyle().
+			Foreground(t.Divider).
+			SetString("┃"),
+
+		barFilled: lipgloss.NewStyle().Foreground(t.BarFilled),
+		barEmpty:  lipgloss.NewStyle().Foreground(t.BarEmpty),
+		text:      lipgloss.NewStyle().Foreground(t.Text),
+		label:     lipgloss.NewStyle().Foreground(t.Divider),
+
+		panel: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(t.Divider).
+			Padding(0, 1),
+
+		cursor: lipgloss.NewStyle().
+			Foreground(t.Option).
+			Bold(true),
+
+		statuses: map[domain.FileStatus]lipgloss.Style{
+			domain.StatusPending: lipgloss.NewStyle().Foreground(t.Text),
+			domain.StatusKept:    lipgloss.NewStyle().Foreground(t.Success),
+			domain.StatusDeleted: lipgloss.NewStyle().Foreground(t.Error).Strikethrough(true),
+			domain.StatusSkipped: lipgloss.NewStyle().Foreground(t.Divider),
+			domain.StatusFailed:  lipgloss.NewStyle().Foreground(t.ErrorText),
+			domain.StatusQueued:  lipgloss.NewStyle().Foreground(t.Processing),
+		},
+	}
+}
+
+func newHelpStyles(t Theme) help.Styles {
+	key := lipgloss.NewStyle().Foreground(t.Option).Bold(true)
+	desc := lipgloss.NewStyle().Foreground(t.Text)
+	sep := lipgloss.NewStyle().Foreground(t.Divider)
+
+	return help.Styles{
+		Ellipsis:       sep,
+		ShortKey:       key,
+		ShortDesc:      desc,
+		ShortSeparator: sep,
+		FullKey:        key,
+		FullDesc:       desc,
+		FullSeparator:  sep,
+	}
+}
+
+// plainStyles renders text as is, without colours, padding or borders.
+func plainStyles() styles {
+	plain := lipgloss.NewStyle()
+	return styles{
+		title:      plain,
+		progress:   plain,
+		file:       plain,
+		option:     plain,
+		success:    plain,
+		err:        plain,
+		errText:    plain,
+		processing: plain,
+		divider:    plain.SetString("|"),
+		barFilled:  plain,
+		barEmpty:   plain,
+		text:       plain,
+		label:      plain,
+		panel:      plain,
+		cursor:     plain,
+		statuses:   map[domain.FileStatus]lipgloss.Style{},
+	}
+}
+
+// glyphs holds the decorations prefixed to view elements.
+type glyphs struct {
+	manage     string
+	processing string
+	wait       string
+	done       string
+	stats      string
+	failed     string
+	err        string
+	file       string
+	action     string
+	exit       string
+	bullet     string
+	ellipsis   string
+	link       string
+	cursor     string
+	mark       string
+	statuses   map[domain.FileStatus]string
+	barFilled  string
+	barEmpty   string
+}
+
+var emojiGlyphs = glyphs{
+	manage:     "📁 ",
+	processing: "⚙️  ",
+	wait:       "⏳ ",
+	done:       "🎉 ",
+	stats:      "✅ ",
+	failed:     "⚠️  ",
+	err:        "❌ ",
+	file:       "📄 ",
+	action:     "❓ ",
+	exit:       "👆 ",
+	bullet:     "•",
+	ellipsis:   "…",
+	link:       "→",
+	cursor:     "▶",
+	mark:       "●",
+	statuses: map[domain.FileStatus]string{
+		domain.StatusPending: "○",
+		domain.StatusKept:    "✔",
+		domain.StatusDeleted: "✘",
+		domain.StatusSkipped: "»",
+		domain.StatusFailed:  "!",
+		domain.StatusQueued:  "⋯",
+	},
+	barFilled: "█",
+	barEmpty:  "░",
+}
+
+var plainGlyphs = glyphs{
+	file:     "File: ",
+	bullet:   "-",
+	ellipsis: "...",
+	link:     "->",
+	cursor:   ">",
+	mark:     "*",
+	statuses: map[domain.FileStatus]string{
+		domain.StatusPending: "[ ]",
+		domain.StatusKept:    "[K]",
+		domain.StatusDeleted: "[D]",
+		domain.StatusSkipped: "[S]",
+		domain.StatusFailed:  "[F]",
+		domain.StatusQueued:  "[Q]",
+	},
+	barFilled: "#",
+	barEmpty:  "-",
+}
+
+// statusStyle returns the style for files with the given status.
+func (s styles) statusStyle(status domain.FileStatus) lipgloss.Style {
+	if style, ok := s.statuses[status]; ok {
+		return style
+	}
+	return s.text
+}
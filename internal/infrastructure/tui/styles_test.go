@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNewTheme(t *testing.T) {
+	t.Run("should return default theme for empty name", func(t *testing.T) {
+		theme, err := NewTheme("", nil)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := theme.Title.(lipgloss.AdaptiveColor); !ok {
+			t.Errorf("Expected adaptive colours, got %T", theme.Title)
+		}
+	})
+
+	t.Run("should return every built-in theme", func(t *testing.T) {
+		for _, name := range []string{"dark", "light", "high-contrast", "monochrome"} {
+			if _, err := NewTheme(name, nil); err != nil {
+				t.Errorf("Expected theme %s, got error %v", name, err)
+			}
+		}
+	})
+
+	t.Run("should use no colours in monochrome theme", func(t *testing.T) {
+		theme, err := NewTheme("monochrome", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for role, color := range theme.roles() {
+			if _, ok := (*color).(lipgloss.NoColor); !ok {
+				t.Errorf("Expected no colour for %s, got %v", role, *color)
+			}
+		}
+	})
+
+	t.Run("should return error for unknown theme", func(t *testing.T) {
+		_, err := NewTheme("neon", nil)
+
+		if err == nil {
+			t.Error("Expected error for unknown theme")
+		}
+	})
+
+	t.Run("should override roles of base theme in custom theme", func(t *testing.T) {
+		custom := map[string]map[string]string{
+			"mine": {
+				"base":  "light",
+				"title": "#ff0000",
+				"file":  "22, 156",
+			},
+		}
+
+		theme, err := NewTheme("mine", custom)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if theme.Title != lipgloss.Color("#ff0000") {
+			t.Errorf("Expected custom title colour, got %v", theme.Title)
+		}
+		if theme.File != (lipgloss.AdaptiveColor{Light: "22", Dark: "156"}) {
+			t.Errorf("Expected adaptive file colour, got %v", theme.File)
+		}
+		if theme.Option != builtinThemes["light"].Option {
+			t.Errorf("Expected option colour from base theme, got %v", theme.Option)
+		}
+	})
+
+	t.Run("should return error for unknown colour role", func(t *testing.T) {
+		custom := map[string]map[string]string{
+			"mine": {"background": "#000000"},
+		}
+
+		_, err := NewTheme("mine", custom)
+
+		if err == nil {
+			t.Error("Expected error for unknown role")
+		}
+	})
+
+	t.Run("should return error for unknown base theme", func(t *testing.T) {
+		custom := map[string]map[string]string{
+			"mine": {"base": "neon"},
+		}
+
+		_, err := NewTheme("mine", custom)
+
+		if err == nil {
+			t.Error("Expected error for unknown base theme")
+		}
+	})
+}
@@ -1,12 +1,18 @@
 package tui
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/golang/mock/gomock"
+	"github.com/mattn/go-runewidth"
 	"github.com/rycln/filer/internal/domain"
 	"github.com/rycln/filer/internal/infrastructure/tui/mocks"
 )
@@ -33,14 +39,24 @@ func TestInitialModel(t *testing.T) {
 		if model.manager != mockManager {
 			t.Error("Manager not set correctly")
 		}
-		if model.errMsg != "" {
-			t.Errorf("Expected empty error message, got %s", model.errMsg)
+		if len(model.faults) != 0 {
+			t.Errorf("Expected no failed operations, got %v", model.faults)
 		}
 	})
 }
 
+// runQueue runs the queued operations of m one after another and
+// delivers their results.
+func runQueue(m Model) Model {
+	for _, op := range m.queue.snapshot() {
+		updated, _ := m.Update(op.run())
+		m = updated.(Model)
+	}
+	return m
+}
+
 func TestModel_Init(t *testing.T) {
-	t.Run("should return nil command", func(t *testing.T) {
+	t.Run("should load metadata of first file", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -51,10 +67,23 @@ func TestModel_Init(t *testing.T) {
 		}
 		model := InitialModel(batch, mockManager)
 
-		cmd := model.Init()
+		meta := domain.FileMeta{Size: 42}
+		mockManager.EXPECT().Metadata("file1.txt").Return(meta, nil)
+		mockManager.EXPECT().Space().Return(domain.TargetSpace{Free: -1, Quota: -1}, nil)
 
-		if cmd != nil {
-			t.Error("Expected nil command from Init")
+		batchMsg, ok := model.Init()().(tea.BatchMsg)
+		if !ok || len(batchMsg) != 2 {
+			t.Fatalf("Expected metadata and space commands from Init, got %v", batchMsg)
+		}
+		msg, ok := batchMsg[0]().(MetaMsg)
+		if !ok {
+			t.Fatalf("Expected MetaMsg, got %T", msg)
+		}
+		if msg.File != "file1.txt" || msg.Meta.Size != 42 {
+			t.Errorf("Expected metadata of file1.txt, got %+v", msg)
+		}
+		if _, ok := batchMsg[1]().(SpaceMsg); !ok {
+			t.Error("Expected SpaceMsg from Init")
 		}
 	})
 }
@@ -109,12 +138,12 @@ func TestModel_Update_FileManageState(t *testing.T) {
 		}
 	})
 
-	t.Run("should transition to ProcessingState on 'k' key", func(t *testing.T) {
+	t.Run("should queue keep and move to next file on 'k' key", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
@@ -127,15 +156,24 @@ func TestModel_Update_FileManageState(t *testing.T) {
 		updatedTeaModel, cmd := model.Update(msg)
 		updatedModel := updatedTeaModel.(Model)
 
-		if updatedModel.state != ProcessingState {
-			t.Errorf("Expected ProcessingState, got %v", updatedModel.state)
+		if updatedModel.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
+		}
+		if updatedModel.batch.CurrentFile() != "file2.txt" {
+			t.Errorf("Expected 'file2.txt', got '%s'", updatedModel.batch.CurrentFile())
+		}
+		if updatedModel.batch.StatusOf("file1.txt") != domain.StatusQueued || updatedModel.queue.len() != 1 {
+			t.Error("Expected keep of file1.txt to be queued")
 		}
 		if cmd == nil {
 			t.Error("Expected keep command")
 		}
+		if !strings.Contains(updatedModel.View(), "1 queued") {
+			t.Error("View should show the queued keep")
+		}
 	})
 
-	t.Run("should transition to ProcessingState on 'd' key", func(t *testing.T) {
+	t.Run("should transition to ProcessingState on 'd' key for the last file", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -187,8 +225,8 @@ func TestModel_Update_FileManageState(t *testing.T) {
 		if updatedModel.state != FileManageState {
 			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
 		}
-		if cmd != nil {
-			t.Error("Expected nil command after skip")
+		if cmd == nil {
+			t.Error("Expected metadata command for next file")
 		}
 	})
 
@@ -223,7 +261,9 @@ func TestModel_Update_FileManageState(t *testing.T) {
 }
 
 func TestModel_Update_ProcessingState(t *testing.T) {
-	t.Run("should handle success message and move to next file", func(t *testing.T) {
+	keep := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}
+
+	t.Run("should record finished operation", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -233,25 +273,51 @@ func TestModel_Update_ProcessingState(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = ProcessingState
 
-		initialProgress := batch.Progress()
-		msg := SuccessMsg{}
-		updatedTeaModel, cmd := model.Update(msg)
-		updatedModel := updatedTeaModel.(Model)
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(nil)
 
-		if updatedModel.batch.Progress() != initialProgress+1 {
-			t.Error("Progress should increment after success")
+		updatedTeaModel, _ := model.Update(keep)
+		updatedModel := runQueue(updatedTeaModel.(Model))
+
+		if updatedModel.batch.StatusOf("file1.txt") != domain.StatusKept {
+			t.Errorf("Expected file1.txt kept, got %v", updatedModel.batch.StatusOf("file1.txt"))
 		}
 		if updatedModel.state != FileManageState {
 			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
 		}
-		if cmd != nil {
-			t.Error("Expected nil command after success")
+		if updatedModel.batch.CurrentFile() != "file2.txt" {
+			t.Errorf("Expected 'file2.txt', got '%s'", updatedModel.batch.CurrentFile())
+		}
+	})
+
+	t.Run("should record decision in stats on success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Delete("file1.txt").Return(nil)
+
+		updatedTeaModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		updatedModel := runQueue(updatedTeaModel.(Model))
+
+		stats := updatedModel.Stats()
+		if stats.Deleted != 1 {
+			t.Errorf("Expected 1 deleted file, got %d", stats.Deleted)
+		}
+		if stats.BytesFreed != 12 {
+			t.Errorf("Expected 12 bytes freed, got %d", stats.BytesFreed)
 		}
 	})
 
-	t.Run("should transition to EndState after last file success", func(t *testing.T) {
+	t.Run("should transition to EndState once the last operation finishes", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -261,11 +327,20 @@ func TestModel_Update_ProcessingState(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = ProcessingState
 
-		msg := SuccessMsg{}
-		updatedTeaModel, cmd := model.Update(msg)
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(nil)
+
+		updatedTeaModel, _ := model.Update(keep)
 		updatedModel := updatedTeaModel.(Model)
+		if updatedModel.state != ProcessingState {
+			t.Fatalf("Expected ProcessingState while the keep runs, got %v", updatedModel.state)
+		}
+		if !strings.Contains(updatedModel.View(), "0 running, 1 queued") {
+			t.Errorf("View should show the queued keep, got %q", updatedModel.View())
+		}
+
+		updatedModel = runQueue(updatedModel)
 
 		if updatedModel.state != EndState {
 			t.Errorf("Expected EndState, got %v", updatedModel.state)
@@ -273,9 +348,6 @@ func TestModel_Update_ProcessingState(t *testing.T) {
 		if !updatedModel.batch.IsComplete() {
 			t.Error("Batch should be complete after last file")
 		}
-		if cmd != nil {
-			t.Error("Expected nil command after success")
-		}
 	})
 
 	t.Run("should handle error message and transition to ErrorState", func(t *testing.T) {
@@ -283,135 +355,169 @@ func TestModel_Update_ProcessingState(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = ProcessingState
 
 		testError := errors.New("test error")
-		msg := ErrorMsg{Err: testError}
-		updatedTeaModel, cmd := model.Update(msg)
-		updatedModel := updatedTeaModel.(Model)
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(testError)
+
+		updatedTeaModel, _ := model.Update(keep)
+		updatedModel := runQueue(updatedTeaModel.(Model))
 
 		if updatedModel.state != ErrorState {
 			t.Errorf("Expected ErrorState, got %v", updatedModel.state)
 		}
-		if updatedModel.errMsg != testError.Error() {
-			t.Errorf("Expected error message '%s', got '%s'", testError.Error(), updatedModel.errMsg)
+		if len(updatedModel.faults) != 1 || updatedModel.faults[0].op.file != "file1.txt" {
+			t.Errorf("Expected failed keep of file1.txt, got %v", updatedModel.faults)
 		}
-		if cmd != nil {
-			t.Error("Expected nil command after error")
+		view := updatedModel.View()
+		if !strings.Contains(view, "file1.txt") || !strings.Contains(view, testError.Error()) {
+			t.Errorf("View should name the failed file and error, got %q", view)
 		}
 	})
 
-	t.Run("should quit on Ctrl+C in ProcessingState", func(t *testing.T) {
+	t.Run("should not interrupt text input with errors", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = ProcessingState
 
-		msg := tea.KeyMsg{Type: tea.KeyCtrlC}
-		updatedTeaModel, cmd := model.Update(msg)
+		updatedTeaModel, _ := model.Update(keep)
+		updatedTeaModel, _ = updatedTeaModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		updatedTeaModel, _ = updatedTeaModel.Update(ErrorMsg{File: "file1.txt", Err: errors.New("test error")})
 		updatedModel := updatedTeaModel.(Model)
 
-		if cmd == nil {
-			t.Error("Expected quit command")
+		if updatedModel.state != FileManageState || !updatedModel.querying {
+			t.Fatalf("Expected filter input to stay open, got state %v", updatedModel.state)
 		}
-		if updatedModel.state != ProcessingState {
-			t.Error("State should not change on quit command")
+
+		updatedTeaModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		updatedModel = updatedTeaModel.(Model)
+
+		if updatedModel.state != ErrorState {
+			t.Errorf("Expected ErrorState after closing the input, got %v", updatedModel.state)
 		}
 	})
-}
 
-func TestModel_Update_EndState(t *testing.T) {
-	t.Run("should quit on any key in EndState", func(t *testing.T) {
+	t.Run("should wait for queued operations before quitting", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = EndState
 
-		msg := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedTeaModel, cmd := model.Update(msg)
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(nil)
+
+		updatedTeaModel, _ := model.Update(keep)
+		updatedTeaModel, cmd := updatedTeaModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
 		updatedModel := updatedTeaModel.(Model)
 
+		if cmd != nil {
+			t.Error("Expected no quit command while the keep is queued")
+		}
+		if updatedModel.state != ProcessingState || !strings.Contains(updatedModel.View(), "exiting when done") {
+			t.Errorf("Expected ProcessingState waiting to exit, got %v", updatedModel.state)
+		}
+
+		op := updatedModel.queue.snapshot()[0]
+		updatedTeaModel, cmd = updatedModel.Update(op.run())
 		if cmd == nil {
-			t.Error("Expected quit command")
+			t.Fatal("Expected quit command after the queue drained")
 		}
-		if updatedModel.state != EndState {
-			t.Error("State should not change on quit command")
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Error("Expected quit command after the queue drained")
+		}
+		if updatedTeaModel.(Model).Stats().Kept != 1 {
+			t.Error("Finished keep should be recorded before quitting")
 		}
 	})
-}
 
-func TestModel_Update_ErrorState(t *testing.T) {
-	t.Run("should quit on any key in ErrorState", func(t *testing.T) {
+	t.Run("should cancel queued operations on Ctrl+C", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = ErrorState
-		model.errMsg = "test error"
 
-		msg := tea.KeyMsg{Type: tea.KeySpace}
-		updatedTeaModel, cmd := model.Update(msg)
+		updatedTeaModel, _ := model.Update(keep)
+		updatedTeaModel, _ = updatedTeaModel.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
 		updatedModel := updatedTeaModel.(Model)
 
+		if updatedModel.state != ProcessingState || !strings.Contains(updatedModel.View(), "cancelling") {
+			t.Errorf("Expected ProcessingState cancelling operations, got %v", updatedModel.state)
+		}
+
+		op := updatedModel.queue.snapshot()[0]
+		updatedTeaModel, cmd := updatedModel.Update(op.run())
 		if cmd == nil {
-			t.Error("Expected quit command")
+			t.Fatal("Expected quit command after the queue drained")
 		}
-		if updatedModel.state != ErrorState {
-			t.Error("State should not change on quit command")
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Error("Expected quit command after the queue drained")
+		}
+		if stats := updatedTeaModel.(Model).Stats(); stats.Skipped != 1 || stats.Failed != 0 {
+			t.Errorf("Cancelled keep should be recorded as skipped, got %+v", stats)
 		}
 	})
-}
 
-func TestModel_Keep_Delete_Commands(t *testing.T) {
-	t.Run("keep command should return SuccessMsg on success", func(t *testing.T) {
+	t.Run("should skip a copy cancelled while running", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
 
-		mockManager.EXPECT().Keep("file1.txt").Return(nil)
+		mockManager.EXPECT().Size("file1.txt").Return(int64(2048), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").DoAndReturn(func(ctx context.Context, _ string) error {
+			domain.ProgressFrom(ctx)(2048)
+			model.cancel()
+			return ctx.Err()
+		})
 
-		cmd := model.keep()
-		msg := cmd()
+		updatedTeaModel, _ := model.Update(keep)
+		updatedModel := updatedTeaModel.(Model)
+		result := updatedModel.queue.snapshot()[0].run()
 
-		switch msg.(type) {
-		case SuccessMsg:
-			// Expected
-		case ErrorMsg:
-			t.Error("Expected SuccessMsg, got ErrorMsg")
-		default:
-			t.Errorf("Expected SuccessMsg, got %T", msg)
+		updatedTeaModel, _ = updatedModel.Update(<-updatedModel.progress)
+		if !strings.Contains(updatedTeaModel.(Model).View(), "100% of 2.0 KiB") {
+			t.Errorf("View should show the copy progress, got %q", updatedTeaModel.(Model).View())
+		}
+
+		updatedTeaModel, _ = updatedTeaModel.Update(result)
+		updatedModel = updatedTeaModel.(Model)
+		if updatedModel.state == ErrorState {
+			t.Error("Cancelled copy should not show the error screen")
+		}
+		if updatedModel.batch.StatusOf("file1.txt") != domain.StatusSkipped {
+			t.Errorf("Expected file1.txt skipped, got %v", updatedModel.batch.StatusOf("file1.txt"))
 		}
 	})
+}
 
-	t.Run("keep command should return ErrorMsg on failure", func(t *testing.T) {
+func TestModel_Update_EndState(t *testing.T) {
+	t.Run("should quit on any key in EndState", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -421,26 +527,27 @@ func TestModel_Keep_Delete_Commands(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
+		model.state = EndState
 
-		expectedErr := errors.New("keep failed")
-		mockManager.EXPECT().Keep("file1.txt").Return(expectedErr)
-
-		cmd := model.keep()
-		msg := cmd()
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+		updatedTeaModel, cmd := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
 
-		switch msg := msg.(type) {
-		case ErrorMsg:
-			if msg.Err != expectedErr {
-				t.Errorf("Expected error %v, got %v", expectedErr, msg.Err)
-			}
-		case SuccessMsg:
-			t.Error("Expected ErrorMsg, got SuccessMsg")
-		default:
-			t.Errorf("Expected ErrorMsg, got %T", msg)
+		if cmd == nil {
+			t.Error("Expected quit command")
+		}
+		if updatedModel.state != EndState {
+			t.Error("State should not change on quit command")
 		}
 	})
+}
 
-	t.Run("delete command should return SuccessMsg on success", func(t *testing.T) {
+func TestModel_Update_ErrorState(t *testing.T) {
+	failure := func(file string, err error) []fault {
+		return []fault{{op: operation{file: file}, err: err}}
+	}
+
+	t.Run("should quit on 'q' key in ErrorState", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -450,23 +557,28 @@ func TestModel_Keep_Delete_Commands(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
+		model.state = ErrorState
+		model.faults = failure("file1.txt", errors.New("test error"))
 
-		mockManager.EXPECT().Delete("file1.txt").Return(nil)
-
-		cmd := model.delete()
-		msg := cmd()
+		msg := tea.KeyMsg{
+			Type:  tea.KeyRunes,
+			Runes: []rune{'q'},
+		}
+		updatedTeaModel, cmd := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
 
-		switch msg.(type) {
-		case SuccessMsg:
-			// Expected
-		case ErrorMsg:
-			t.Error("Expected SuccessMsg, got ErrorMsg")
-		default:
-			t.Errorf("Expected SuccessMsg, got %T", msg)
+		if cmd == nil {
+			t.Error("Expected quit command")
+		}
+		if updatedModel.state != ErrorState {
+			t.Error("State should not change on quit command")
+		}
+		if len(updatedModel.failed) != 1 {
+			t.Errorf("Expected unresolved failure recorded, got %v", updatedModel.failed)
 		}
 	})
 
-	t.Run("delete command should return ErrorMsg on failure", func(t *testing.T) {
+	t.Run("should ignore unrelated keys in ErrorState", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -476,28 +588,22 @@ func TestModel_Keep_Delete_Commands(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
+		model.state = ErrorState
+		model.faults = failure("file1.txt", errors.New("test error"))
 
-		expectedErr := errors.New("delete failed")
-		mockManager.EXPECT().Delete("file1.txt").Return(expectedErr)
-
-		cmd := model.delete()
-		msg := cmd()
+		msg := tea.KeyMsg{Type: tea.KeySpace}
+		updatedTeaModel, cmd := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
 
-		switch msg := msg.(type) {
-		case ErrorMsg:
-			if msg.Err != expectedErr {
-				t.Errorf("Expected error %v, got %v", expectedErr, msg.Err)
-			}
-		case SuccessMsg:
-			t.Error("Expected ErrorMsg, got SuccessMsg")
-		default:
-			t.Errorf("Expected ErrorMsg, got %T", msg)
+		if cmd != nil {
+			t.Error("Expected nil command")
+		}
+		if updatedModel.state != ErrorState {
+			t.Errorf("Expected ErrorState, got %v", updatedModel.state)
 		}
 	})
-}
 
-func TestModel_View(t *testing.T) {
-	t.Run("should render file manage view", func(t *testing.T) {
+	t.Run("should retry failed operation on 'r' key", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -507,84 +613,1671 @@ func TestModel_View(t *testing.T) {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
+		model.state = ErrorState
+		model.prev = FileManageState
+		model.faults = []fault{{
+			op:  operation{file: "file1.txt", act: deleteAction, run: model.process(deleteAction, "file1.txt")},
+			err: errors.New("test error"),
+		}}
 
-		view := model.View()
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Delete("file1.txt").Return(nil)
 
-		if !strings.Contains(view, "File Manager") {
-			t.Error("View should contain 'File Manager' title")
+		msg := tea.KeyMsg{
+			Type:  tea.KeyRunes,
+			Runes: []rune{'r'},
 		}
-		if !strings.Contains(view, "file1.txt") {
-			t.Error("View should contain current filename")
+		updatedTeaModel, cmd := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
+
+		if updatedModel.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
 		}
-		if !strings.Contains(view, "Keep") || !strings.Contains(view, "Delete") {
-			t.Error("View should contain action options")
+		if cmd == nil || updatedModel.batch.StatusOf("file1.txt") != domain.StatusQueued {
+			t.Fatal("Expected retried delete to be queued")
+		}
+
+		updatedModel = runQueue(updatedModel)
+
+		if updatedModel.batch.StatusOf("file1.txt") != domain.StatusDeleted {
+			t.Errorf("Expected file1.txt deleted, got %v", updatedModel.batch.StatusOf("file1.txt"))
 		}
 	})
 
-	t.Run("should render processing view", func(t *testing.T) {
+	t.Run("should skip failed file on 's' key", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
+		batch.SetStatus(domain.StatusQueued)
+		batch.NextFile()
 		model := InitialModel(batch, mockManager)
-		model.state = ProcessingState
+		model.state = ErrorState
+		model.prev = FileManageState
+		model.faults = failure("file1.txt", errors.New("test error"))
 
-		view := model.View()
+		msg := tea.KeyMsg{
+			Type:  tea.KeyRunes,
+			Runes: []rune{'s'},
+		}
+		updatedTeaModel, _ := model.Update(msg)
+		updatedModel := updatedTeaModel.(Model)
 
-		if !strings.Contains(view, "Processing Files") {
-			t.Error("View should contain 'Processing Files' title")
+		if updatedModel.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
+		}
+		if updatedModel.batch.CurrentFile() != "file2.txt" {
+			t.Errorf("Expected 'file2.txt', got '%s'", updatedModel.batch.CurrentFile())
 		}
-		if !strings.Contains(view, "Processing...") {
-			t.Error("View should contain processing indicator")
+		if updatedModel.batch.StatusOf("file1.txt") != domain.StatusFailed {
+			t.Errorf("Expected file1.txt failed, got %v", updatedModel.batch.StatusOf("file1.txt"))
+		}
+		if len(updatedModel.failed) != 1 || updatedModel.failed[0].name != "file1.txt" {
+			t.Errorf("Expected file1.txt recorded as failed, got %v", updatedModel.failed)
 		}
 	})
 
-	t.Run("should render end view", func(t *testing.T) {
+	t.Run("should skip similar errors after 'i' key", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt", "file3.txt", "file4.txt"})
 		if err != nil {
 			t.Fatalf("Failed to create batch: %v", err)
 		}
 		model := InitialModel(batch, mockManager)
-		model.state = EndState
 
-		view := model.View()
+		permErr := func(name string) error {
+			return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+		}
+		for _, name := range []string{"file1.txt", "file2.txt", "file3.txt"} {
+			mockManager.EXPECT().Size(name).Return(int64(1), nil)
+			mockManager.EXPECT().Delete(name).Return(permErr(name))
+		}
 
-		if !strings.Contains(view, "Processing Complete") {
-			t.Error("View should contain completion message")
+		deleteKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}
+		updatedTeaModel, _ := model.Update(deleteKey)
+		updatedTeaModel, _ = updatedTeaModel.Update(deleteKey)
+		updatedModel := runQueue(updatedTeaModel.(Model))
+		if updatedModel.state != ErrorState || len(updatedModel.faults) != 2 {
+			t.Fatalf("Expected two failures in ErrorState, got %v", updatedModel.state)
 		}
-		if !strings.Contains(view, "Processed 1 files") {
-			t.Error("View should contain file count")
+		if !strings.Contains(updatedModel.View(), "1 other file failed") {
+			t.Errorf("View should count the other failure, got %q", updatedModel.View())
 		}
-	})
 
-	t.Run("should render error view", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
+		updatedTeaModel, _ = updatedModel.Update(tea.KeyMsg{
+			Type:  tea.KeyRunes,
+			Runes: []rune{'i'},
+		})
+		updatedModel = updatedTeaModel.(Model)
 
-		mockManager := mocks.NewMockFileManager(ctrl)
-		batch, err := domain.NewFileBatch([]string{"file1.txt"})
-		if err != nil {
-			t.Fatalf("Failed to create batch: %v", err)
+		if updatedModel.state != FileManageState {
+			t.Fatalf("Expected both failures dismissed, got %v", updatedModel.state)
 		}
-		model := InitialModel(batch, mockManager)
-		model.state = ErrorState
-		model.errMsg = "test error message"
 
+		updatedTeaModel, _ = updatedModel.Update(deleteKey)
+		updatedModel = runQueue(updatedTeaModel.(Model))
+
+		if updatedModel.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", updatedModel.state)
+		}
+		if updatedModel.batch.CurrentFile() != "file4.txt" {
+			t.Errorf("Expected 'file4.txt', got '%s'", updatedModel.batch.CurrentFile())
+		}
+		if len(updatedModel.failed) != 3 {
+			t.Errorf("Expected 3 failed files, got %d", len(updatedModel.failed))
+		}
+	})
+}
+
+func TestModel_Keep_Delete_Commands(t *testing.T) {
+	t.Run("keep command should return SuccessMsg on success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithTarget())
+
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(nil)
+
+		cmd := model.keep()
+		msg := cmd()
+
+		switch msg := msg.(type) {
+		case SuccessMsg:
+			if msg.Size != 12 {
+				t.Errorf("Expected size 12, got %d", msg.Size)
+			}
+		case ErrorMsg:
+			t.Error("Expected SuccessMsg, got ErrorMsg")
+		default:
+			t.Errorf("Expected SuccessMsg, got %T", msg)
+		}
+	})
+
+	t.Run("keep command should move nothing without target", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(nil)
+
+		if msg, ok := model.keep()().(SuccessMsg); !ok || msg.Size != 0 {
+			t.Errorf("Expected SuccessMsg moving nothing, got %+v", msg)
+		}
+	})
+
+	t.Run("keep command should return ErrorMsg on failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		expectedErr := errors.New("keep failed")
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "file1.txt").Return(expectedErr)
+
+		cmd := model.keep()
+		msg := cmd()
+
+		switch msg := msg.(type) {
+		case ErrorMsg:
+			if msg.Err != expectedErr {
+				t.Errorf("Expected error %v, got %v", expectedErr, msg.Err)
+			}
+		case SuccessMsg:
+			t.Error("Expected ErrorMsg, got SuccessMsg")
+		default:
+			t.Errorf("Expected ErrorMsg, got %T", msg)
+		}
+	})
+
+	t.Run("delete command should return SuccessMsg on success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Delete("file1.txt").Return(nil)
+
+		cmd := model.delete()
+		msg := cmd()
+
+		switch msg.(type) {
+		case SuccessMsg:
+			// Expected
+		case ErrorMsg:
+			t.Error("Expected SuccessMsg, got ErrorMsg")
+		default:
+			t.Errorf("Expected SuccessMsg, got %T", msg)
+		}
+	})
+
+	t.Run("delete command should return ErrorMsg on failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		expectedErr := errors.New("delete failed")
+		mockManager.EXPECT().Size("file1.txt").Return(int64(12), nil)
+		mockManager.EXPECT().Delete("file1.txt").Return(expectedErr)
+
+		cmd := model.delete()
+		msg := cmd()
+
+		switch msg := msg.(type) {
+		case ErrorMsg:
+			if msg.Err != expectedErr {
+				t.Errorf("Expected error %v, got %v", expectedErr, msg.Err)
+			}
+		case SuccessMsg:
+			t.Error("Expected ErrorMsg, got SuccessMsg")
+		default:
+			t.Errorf("Expected ErrorMsg, got %T", msg)
+		}
+	})
+}
+
+func TestModel_View(t *testing.T) {
+	t.Run("should render file manage view", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		view := model.View()
+
+		if !strings.Contains(view, "File Manager") {
+			t.Error("View should contain 'File Manager' title")
+		}
+		if !strings.Contains(view, "file1.txt") {
+			t.Error("View should contain current filename")
+		}
+		if !strings.Contains(view, "Keep") || !strings.Contains(view, "Delete") {
+			t.Error("View should contain action options")
+		}
+	})
+
+	t.Run("should render processing view", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.state = ProcessingState
+		model.queue.add(operation{file: "file1.txt", act: copyAction})
+		model.queue.acquire(context.Background(), "file1.txt")
+		defer model.queue.release()
+		updated, _ := model.Update(ProgressMsg{File: "file1.txt", Copied: 512, Total: 2048})
+
+		view := updated.(Model).View()
+
+		if !strings.Contains(view, "Processing Files") {
+			t.Error("View should contain 'Processing Files' title")
+		}
+		if !strings.Contains(view, "1 running, 0 queued") || !strings.Contains(view, "Copying file1.txt") {
+			t.Errorf("View should show the running copy, got %q", view)
+		}
+		if !strings.Contains(view, "25% of 2.0 KiB") {
+			t.Errorf("View should show the copy progress, got %q", view)
+		}
+	})
+
+	t.Run("should render end view", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.state = EndState
+
+		view := model.View()
+
+		if !strings.Contains(view, "Processing Complete") {
+			t.Error("View should contain completion message")
+		}
+		if !strings.Contains(view, "Processed 0 of 1 files") {
+			t.Error("View should contain file count")
+		}
+	})
+
+	t.Run("should list failed files in end view", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.state = EndState
+		model.failed = []failedFile{{name: "file1.txt", err: "permission denied"}}
+
+		view := model.View()
+
+		if !strings.Contains(view, "file1.txt: permission denied") {
+			t.Error("View should list failed files")
+		}
+	})
+
+	t.Run("should render error view", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.state = ErrorState
+		model.faults = []fault{{op: operation{file: "file1.txt"}, err: errors.New("test error message")}}
+
+		view := model.View()
+
+		if !strings.Contains(view, "Error Occurred") {
+			t.Error("View should contain error title")
+		}
+		if !strings.Contains(view, "test error message") {
+			t.Error("View should contain error message")
+		}
+	})
+}
+
+func TestModel_View_Plain(t *testing.T) {
+	t.Run("should render file manage view without emoji", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		view := model.View()
+
+		if !strings.Contains(view, "File: file1.txt") {
+			t.Error("View should contain current filename")
+		}
+		if !strings.Contains(view, "[k] Keep") {
+			t.Error("View should contain bracketed key options")
+		}
+		for _, r := range view {
+			if r > 127 {
+				t.Fatalf("Plain view should be ASCII, found %q in %q", r, view)
+			}
+		}
+	})
+
+	t.Run("should render ascii progress bar", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		progressBar := model.createProgressBar(12, 40)
+
+		expected := "[" + strings.Repeat("#", 9) + strings.Repeat("-", 21) + "] 12/40 (30.0%)"
+		if progressBar != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, progressBar)
+		}
+	})
+
+	t.Run("should list help bindings one per line", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+		model.showHelp = true
+
+		view := model.View()
+
+		for _, want := range []string{"\nk: keep\n", "\nd: delete\n"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("Help should list one binding per line, got %q", view)
+			}
+		}
+	})
+}
+
+func TestModel_View_Responsive(t *testing.T) {
+	t.Run("should track window size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		updatedTeaModel, cmd := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		updatedModel := updatedTeaModel.(Model)
+
+		if updatedModel.width != 120 || updatedModel.height != 40 {
+			t.Errorf("Expected size 120x40, got %dx%d", updatedModel.width, updatedModel.height)
+		}
+		if cmd != nil {
+			t.Error("Expected nil command")
+		}
+	})
+
+	t.Run("should stretch progress bar to window width", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+		model.width = 80
+		model.height = 24
+
+		progressBar := model.createProgressBar(1, 2)
+
+		if w := runewidth.StringWidth(progressBar); w != 80 {
+			t.Errorf("Expected progress bar width 80, got %d", w)
+		}
+	})
+
+	t.Run("should stretch metadata panel to window width", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.metas["file1.txt"] = MetaMsg{File: "file1.txt", Meta: domain.FileMeta{Size: 2048, Mode: 0644}}
+
+		for _, width := range []int{60, 100} {
+			model.width = width
+			model.height = 24
+
+			for _, line := range strings.Split(model.metaView(), "\n") {
+				if w := lipgloss.Width(line); w != width {
+					t.Errorf("Expected panel width %d, got %d in %q", width, w, line)
+				}
+			}
+		}
+	})
+
+	t.Run("should truncate long filenames in the middle", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		name := strings.Repeat("x", 100) + ".txt"
+		batch, err := domain.NewFileBatch([]string{name})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+		model.width = 50
+		model.height = 24
+
+		view := model.View()
+
+		if strings.Contains(view, name) {
+			t.Error("Long filename should be truncated")
+		}
+		if !strings.Contains(view, "...") || !strings.Contains(view, "x.txt") {
+			t.Errorf("Truncated filename should keep its extension, got %q", view)
+		}
+	})
+
+	t.Run("should switch to single line below minimum size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+		model.width = 30
+		model.height = 5
+
+		view := model.View()
+
+		if strings.Contains(view, "\n") {
+			t.Errorf("Compact view should be a single line, got %q", view)
+		}
+		if !strings.Contains(view, "0/2 file1.txt") {
+			t.Errorf("Compact view should contain progress and filename, got %q", view)
+		}
+		if runewidth.StringWidth(view) > 30 {
+			t.Errorf("Compact view should fit the width, got %q", view)
+		}
+	})
+}
+
+func TestModel_MetaPanel(t *testing.T) {
+	t.Run("should show loading until metadata arrives", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager)
+
+		if !strings.Contains(model.View(), "Loading metadata") {
+			t.Error("View should show loading placeholder")
+		}
+	})
+
+	t.Run("should render metadata of current file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		msg := MetaMsg{
+			File: "file1.txt",
+			Meta: domain.FileMeta{
+				Size:       2048,
+				Mode:       0644,
+				ModTime:    time.Now().Add(-3 * 31 * 24 * time.Hour),
+				Owner:      "alice",
+				Group:      "staff",
+				Links:      2,
+				Symlink:    true,
+				LinkTarget: "/data/real.txt",
+			},
+		}
+		updatedTeaModel, _ := model.Update(msg)
+		view := updatedTeaModel.(Model).View()
+
+		for _, want := range []string{"2.0 KiB", "3 months ago", "-rw-r--r--", "alice:staff", "Links     2", "-> /data/real.txt"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+	})
+
+	t.Run("should not show metadata of other files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		updatedTeaModel, _ := model.Update(MetaMsg{File: "file2.txt", Meta: domain.FileMeta{Size: 4096}})
+		view := updatedTeaModel.(Model).View()
+
+		if !strings.Contains(view, "Loading metadata") || strings.Contains(view, "4.0 KiB") {
+			t.Errorf("Metadata of other files should not be shown, got %q", view)
+		}
+	})
+
+	t.Run("should show image metadata", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"IMG_1.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		updatedTeaModel, _ := model.Update(MetaMsg{
+			File: "IMG_1.jpg",
+			Meta: domain.FileMeta{
+				Camera:  "Google Pixel 7",
+				Taken:   time.Date(2023, 7, 14, 18, 30, 0, 0, time.Local),
+				Width:   3072,
+				Height:  4080,
+				Rotated: true,
+			},
+		})
+		view := updatedTeaModel.(Model).View()
+
+		for _, want := range []string{"Google Pixel 7", "2023-07-14 18:30", "3072x4080 (rotated)", "GPS       no"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+	})
+
+	t.Run("should prefetch metadata of the next file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt", "file3.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		mockManager.EXPECT().Metadata("file1.txt").Return(domain.FileMeta{Size: 1}, nil)
+		mockManager.EXPECT().Metadata("file2.txt").Return(domain.FileMeta{Size: 2048}, nil)
+
+		batchMsg, ok := model.Init()().(tea.BatchMsg)
+		if !ok || len(batchMsg) != 2 {
+			t.Fatalf("Expected metadata and space commands, got %v", batchMsg)
+		}
+		metaMsg, ok := batchMsg[0]().(tea.BatchMsg)
+		if !ok || len(metaMsg) != 2 {
+			t.Fatalf("Expected metadata of current and next file, got %v", metaMsg)
+		}
+		for _, cmd := range metaMsg {
+			updated, _ := model.Update(cmd())
+			model = updated.(Model)
+		}
+
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		model = updated.(Model)
+		if !strings.Contains(model.View(), "2.0 KiB") {
+			t.Errorf("Prefetched metadata should be shown at once, got %q", model.View())
+		}
+
+		mockManager.EXPECT().Metadata("file3.txt").Return(domain.FileMeta{}, nil)
+		if msg, ok := cmd().(MetaMsg); !ok || msg.File != "file3.txt" {
+			t.Errorf("Expected only the file after next to be loaded, got %v", msg)
+		}
+	})
+
+	t.Run("should load metadata again after an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"scan.pdf"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+
+		updated, _ := model.Update(MetaMsg{File: "scan.pdf", Err: errors.New("still being written")})
+		model = updated.(Model)
+
+		mockManager.EXPECT().Metadata("scan.pdf").Return(domain.FileMeta{Size: 2048}, nil)
+		if msg, ok := model.fetchMeta("scan.pdf")().(MetaMsg); !ok || msg.Err != nil {
+			t.Errorf("Expected metadata loaded again, got %+v", msg)
+		}
+	})
+
+	t.Run("should drop metadata of files that left the source", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"scan.pdf", "other.pdf"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		model := InitialModel(batch, mockManager, WithPlain())
+		updated, _ := model.Update(MetaMsg{File: "scan.pdf", Meta: domain.FileMeta{Size: 2048}})
+		model = updated.(Model)
+
+		model = model.recordFile("scan.pdf", domain.StatusKept, 2048)
+
+		if _, ok := model.metas["scan.pdf"]; ok {
+			t.Error("Metadata of a kept file should be dropped")
+		}
+	})
+}
+
+func TestModel_Space(t *testing.T) {
+	newModel := func(t *testing.T) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, WithPlain()), mockManager
+	}
+
+	t.Run("should hide target space while unknown", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		updated, _ := model.Update(SpaceMsg{Err: errors.New("statfs failed")})
+
+		if strings.Contains(updated.(Model).View(), "Target:") {
+			t.Error("Unknown target space should not be shown")
+		}
+	})
+
+	t.Run("should show free space and quota left", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		updated, _ := model.Update(SpaceMsg{Space: domain.TargetSpace{Free: 3 << 30, Quota: 512 << 20}})
+
+		view := updated.(Model).View()
+		if !strings.Contains(view, "Target: 3.0 GiB free, 512.0 MiB of quota left") {
+			t.Errorf("Expected target space in view, got %q", view)
+		}
+	})
+
+	t.Run("should warn when the current file does not fit", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		updated, _ := model.Update(SpaceMsg{Space: domain.TargetSpace{Free: 1024, Quota: -1}})
+		updated, _ = updated.(Model).Update(MetaMsg{File: "file1.txt", Meta: domain.FileMeta{Size: 2048}})
+
+		if !strings.Contains(updated.(Model).View(), "2.0 KiB does not fit into the target") {
+			t.Errorf("Expected space warning, got %q", updated.(Model).View())
+		}
+	})
+
+	t.Run("should refresh target space after a file is kept", func(t *testing.T) {
+		model, mockManager := newModel(t)
+		model.queue.add(operation{file: "file1.txt", act: keepAction})
+
+		updated, cmd := model.Update(SuccessMsg{File: "file1.txt", Size: 1024})
+		model = updated.(Model)
+
+		mockManager.EXPECT().Space().Return(domain.TargetSpace{Free: 4096, Quota: -1}, nil)
+		updated, _ = model.Update(cmd())
+		if got := updated.(Model).space.Free; got != 4096 {
+			t.Errorf("Expected refreshed free space, got %d", got)
+		}
+	})
+
+	t.Run("should group space errors by kind", func(t *testing.T) {
+		err := fmt.Errorf("%w: needs 2.0 KiB, 1.0 KiB free", domain.ErrNoSpace)
+
+		if got := errorKind(err); got != domain.ErrNoSpace.Error() {
+			t.Errorf("Expected %q, got %q", domain.ErrNoSpace.Error(), got)
+		}
+	})
+}
+
+func TestModel_ListPane(t *testing.T) {
+	newModel := func(t *testing.T, opts ...Option) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt", "file3.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, opts...), mockManager
+	}
+
+	press := func(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+		updated, cmd := m.Update(msg)
+		return updated.(Model), cmd
+	}
+
+	runes := func(r rune) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+	}
+
+	t.Run("should move cursor with navigation keys", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, cmd := press(model, tea.KeyMsg{Type: tea.KeyDown})
+		if model.batch.Cursor() != 1 {
+			t.Errorf("Expected cursor 1 after down, got %d", model.batch.Cursor())
+		}
+		if cmd == nil {
+			t.Error("Expected metadata command after moving")
+		}
+
+		model, _ = press(model, runes('G'))
+		if model.batch.Cursor() != 2 {
+			t.Errorf("Expected cursor 2 after G, got %d", model.batch.Cursor())
+		}
+
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyDown})
+		if model.batch.Cursor() != 2 {
+			t.Errorf("Expected cursor to stay at last file, got %d", model.batch.Cursor())
+		}
+
+		model, _ = press(model, runes('g'))
+		if model.batch.Cursor() != 0 {
+			t.Errorf("Expected cursor 0 after g, got %d", model.batch.Cursor())
+		}
+	})
+
+	t.Run("should act on jumped-to file", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, runes('G'))
+		model, _ = press(model, runes('k'))
+		updated, _ := model.Update(SuccessMsg{File: "file3.txt", Size: 10})
+		model = updated.(Model)
+
+		if model.batch.Status(2) != domain.StatusKept {
+			t.Errorf("Expected last file kept, got %s", model.batch.Status(2))
+		}
+		if model.batch.Status(0) != domain.StatusPending {
+			t.Errorf("Expected first file still pending, got %s", model.batch.Status(0))
+		}
+		if model.batch.CurrentFile() != "file1.txt" {
+			t.Errorf("Expected cursor to wrap to file1.txt, got %s", model.batch.CurrentFile())
+		}
+	})
+
+	t.Run("should ignore actions on decided files", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, runes('k'))
+		updated, _ := model.Update(SuccessMsg{File: "file1.txt"})
+		model = updated.(Model)
+		model, _ = press(model, runes('g'))
+
+		model, cmd := press(model, runes('d'))
+		if cmd != nil || model.state != FileManageState {
+			t.Error("Delete should be ignored for a kept file")
+		}
+		if !strings.Contains(model.View(), "Already kept") {
+			t.Error("View should show the decided status")
+		}
+	})
+
+	t.Run("should redecide skipped files", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, runes('s'))
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyUp})
+		model, _ = press(model, runes('d'))
+		updated, _ := model.Update(SuccessMsg{File: "file1.txt", Size: 5})
+		model = updated.(Model)
+
+		stats := model.Stats()
+		if stats.Skipped != 0 || stats.Deleted != 1 {
+			t.Errorf("Expected skip replaced by delete, got %+v", stats.DecisionCounts)
+		}
+	})
+
+	t.Run("should toggle list pane", func(t *testing.T) {
+		model, _ := newModel(t, WithPlain())
+
+		if strings.Contains(model.View(), "[ ] file2.txt") {
+			t.Error("List pane should be hidden by default")
+		}
+
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyTab})
+		view := model.View()
+		for _, want := range []string{"> [ ] file1.txt", "  [ ] file2.txt", "  [ ] file3.txt"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+	})
+
+	t.Run("should mark statuses in list pane", func(t *testing.T) {
+		model, _ := newModel(t, WithPlain(), WithList())
+
+		model, _ = press(model, runes('s'))
+		view := model.View()
+
+		if !strings.Contains(view, "[S] file1.txt") {
+			t.Errorf("View should mark skipped file, got %q", view)
+		}
+		if !strings.Contains(view, "> [ ] file2.txt") {
+			t.Errorf("View should place cursor on file2.txt, got %q", view)
+		}
+	})
+
+	t.Run("should place list pane beside main view on wide terminals", func(t *testing.T) {
+		for _, tc := range []struct {
+			width  int
+			beside bool
+		}{
+			{width: 120, beside: true},
+			{width: 60, beside: false},
+		} {
+			model, _ := newModel(t, WithList())
+
+			updated, _ := model.Update(tea.WindowSizeMsg{Width: tc.width, Height: 30})
+			view := updated.(Model).View()
+
+			beside := false
+			for _, line := range strings.Split(view, "\n") {
+				if strings.Contains(line, "File Manager") && strings.Contains(line, "file1.txt") {
+					beside = true
+				}
+			}
+			if beside != tc.beside {
+				t.Errorf("Width %d: expected pane beside main view %v, got %q", tc.width, tc.beside, view)
+			}
+		}
+	})
+}
+
+func TestModel_Filter(t *testing.T) {
+	newModel := func(t *testing.T, opts ...Option) Model {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"a.jpg", "b.txt", "c.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, opts...)
+	}
+
+	press := func(m Model, msgs ...tea.Msg) Model {
+		for _, msg := range msgs {
+			updated, _ := m.Update(msg)
+			m = updated.(Model)
+		}
+		return m
+	}
+
+	typed := func(text string) []tea.Msg {
+		var msgs []tea.Msg
+		for _, r := range text {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return msgs
+	}
+
+	slash := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}
+
+	t.Run("should filter view while typing", func(t *testing.T) {
+		model := newModel(t, WithPlain())
+
+		model = press(model, slash)
+		if !model.querying {
+			t.Fatal("Expected filter input to open on '/'")
+		}
+		model = press(model, typed("txt")...)
+
+		if model.batch.TotalFiles() != 1 || model.batch.CurrentFile() != "b.txt" {
+			t.Errorf("Expected only b.txt visible, got %d files, current %q", model.batch.TotalFiles(), model.batch.CurrentFile())
+		}
+		view := model.View()
+		for _, want := range []string{"Filter (fuzzy): txt", "0/1"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+	})
+
+	t.Run("should not act on keys typed into the filter", func(t *testing.T) {
+		model := newModel(t)
+
+		model = press(model, slash)
+		model = press(model, typed("kdq")...)
+
+		if model.state != FileManageState {
+			t.Error("Typing into the filter should not start an operation")
+		}
+		if model.query.Value() != "kdq" {
+			t.Errorf("Expected query 'kdq', got %q", model.query.Value())
+		}
+	})
+
+	t.Run("should keep filter after enter and clear it with esc", func(t *testing.T) {
+		model := newModel(t)
+
+		model = press(model, slash)
+		model = press(model, typed("jpg")...)
+		model = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+		if model.querying || !model.batch.Filtered() {
+			t.Fatal("Expected input closed with filter still applied")
+		}
+
+		model = press(model, tea.KeyMsg{Type: tea.KeyEsc})
+
+		if model.batch.Filtered() || model.batch.TotalFiles() != 3 {
+			t.Errorf("Expected all files after esc, got %d", model.batch.TotalFiles())
+		}
+		if model.query.Value() != "" {
+			t.Errorf("Expected empty query, got %q", model.query.Value())
+		}
+	})
+
+	t.Run("should switch to regex mode", func(t *testing.T) {
+		model := newModel(t, WithPlain())
+
+		model = press(model, slash)
+		model = press(model, typed("c")...)
+		if model.batch.TotalFiles() != 1 {
+			t.Errorf("Expected fuzzy match of c.jpg only, got %d files", model.batch.TotalFiles())
+		}
+
+		model = press(model, tea.KeyMsg{Type: tea.KeyCtrlR})
+		model = press(model, typed("[")...)
+
+		if model.queryErr == nil {
+			t.Error("Expected invalid regex to be reported")
+		}
+		if model.batch.TotalFiles() != 1 {
+			t.Errorf("Invalid regex should keep previous view, got %d files", model.batch.TotalFiles())
+		}
+		if !strings.Contains(model.View(), "Filter (regex)") {
+			t.Error("View should show regex mode")
+		}
+	})
+
+	t.Run("should ignore actions when nothing matches", func(t *testing.T) {
+		model := newModel(t, WithPlain())
+
+		model = press(model, slash)
+		model = press(model, typed("zzz")...)
+		model = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+		model = press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+
+		if model.state != FileManageState {
+			t.Error("Keep should be ignored without a current file")
+		}
+		if !strings.Contains(model.View(), "No files match the filter") {
+			t.Error("View should report empty filter result")
+		}
+	})
+
+	t.Run("should keep decisions when filter changes", func(t *testing.T) {
+		model := newModel(t)
+
+		model = press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		model = press(model, slash)
+		model = press(model, typed("jpg")...)
+
+		if model.batch.Status(0) != domain.StatusSkipped {
+			t.Errorf("Expected a.jpg still skipped, got %s", model.batch.Status(0))
+		}
+		if model.batch.CurrentFile() != "c.jpg" {
+			t.Errorf("Expected cursor on pending c.jpg, got %q", model.batch.CurrentFile())
+		}
+	})
+}
+
+func TestModel_Bulk(t *testing.T) {
+	newModel := func(t *testing.T, files []string, opts ...Option) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch(files)
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, opts...), mockManager
+	}
+
+	press := func(m Model, msgs ...tea.Msg) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			var updated tea.Model
+			updated, cmd = m.Update(msg)
+			m = updated.(Model)
+		}
+		return m, cmd
+	}
+
+	runes := func(text string) []tea.Msg {
+		var msgs []tea.Msg
+		for _, r := range text {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return msgs
+	}
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	// drain feeds bulk results back into the model until the operation ends.
+	drain := func(t *testing.T, m Model, cmd tea.Cmd) Model {
+		for m.state == BulkState {
+			if cmd == nil {
+				t.Fatal("Expected command while bulk operation runs")
+			}
+			m, cmd = press(m, cmd())
+		}
+		return m
+	}
+
+	t.Run("should mark file and move down on space", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"}, WithPlain(), WithList())
+
+		model, _ = press(model, space)
+
+		if !model.batch.IsMarked(0) {
+			t.Error("Expected first file marked")
+		}
+		if model.batch.Cursor() != 1 {
+			t.Errorf("Expected cursor on second file, got %d", model.batch.Cursor())
+		}
+		view := model.View()
+		for _, want := range []string{" *[ ] a.tmp", "1 file marked"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+	})
+
+	t.Run("should mark visual range", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"})
+
+		model, _ = press(model, runes("V")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyDown})
+		if !strings.Contains(model.View(), "Selecting 2 files") {
+			t.Error("View should show selection size")
+		}
+		model, _ = press(model, runes("V")...)
+
+		marked := model.batch.MarkedFiles()
+		if len(marked) != 2 || marked[1] != "b.tmp" {
+			t.Errorf("Expected [a.tmp b.tmp] marked, got %v", marked)
+		}
+		if model.visual {
+			t.Error("Visual mode should end after marking")
+		}
+	})
+
+	t.Run("should mark files matching pattern", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"})
+
+		model, _ = press(model, runes("*tmp")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+		marked := model.batch.MarkedFiles()
+		if len(marked) != 2 || marked[0] != "a.tmp" || marked[1] != "b.tmp" {
+			t.Errorf("Expected tmp files marked, got %v", marked)
+		}
+		if model.marking {
+			t.Error("Mark input should close after enter")
+		}
+	})
+
+	t.Run("should clear marks", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.tmp", "b.tmp"})
+
+		model, _ = press(model, space)
+		model, _ = press(model, runes("u")...)
+
+		if len(model.batch.MarkedFiles()) != 0 {
+			t.Error("Expected marks cleared")
+		}
+	})
+
+	t.Run("should delete all marked files", func(t *testing.T) {
+		model, mockManager := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"})
+
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(10), nil).Times(2)
+		mockManager.EXPECT().Delete("a.tmp").Return(nil)
+		mockManager.EXPECT().Delete("b.tmp").Return(nil)
+		mockManager.EXPECT().Metadata("c.jpg").Return(domain.FileMeta{}, nil).AnyTimes()
+
+		model, _ = press(model, runes("*tmp")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+		model, cmd := press(model, runes("d")...)
+
+		if model.state != BulkState {
+			t.Fatalf("Expected BulkState, got %v", model.state)
+		}
+		model = drain(t, model, cmd)
+
+		if model.state != FileManageState {
+			t.Errorf("Expected FileManageState after bulk, got %v", model.state)
+		}
+		if model.batch.CurrentFile() != "c.jpg" {
+			t.Errorf("Expected cursor on remaining c.jpg, got %q", model.batch.CurrentFile())
+		}
+		stats := model.Stats()
+		if stats.Deleted != 2 || stats.BytesFreed != 20 {
+			t.Errorf("Expected 2 deleted files and 20 bytes, got %+v", stats)
+		}
+		if len(model.batch.MarkedFiles()) != 0 {
+			t.Error("Deleted files should lose their marks")
+		}
+	})
+
+	t.Run("should report combined errors", func(t *testing.T) {
+		model, mockManager := newModel(t, []string{"a.tmp", "b.tmp", "c.tmp"}, WithPlain())
+
+		permErr := func(name string) error {
+			return &fs.PathError{Op: "rename", Path: name, Err: fs.ErrPermission}
+		}
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(0), nil).Times(3)
+		mockManager.EXPECT().Keep(gomock.Any(), "a.tmp").Return(permErr("a.tmp"))
+		mockManager.EXPECT().Keep(gomock.Any(), "b.tmp").Return(nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "c.tmp").Return(permErr("c.tmp"))
+
+		model, _ = press(model, runes("*")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+		model, cmd := press(model, runes("k")...)
+		model = drain(t, model, cmd)
+
+		if model.state != ReportState {
+			t.Fatalf("Expected ReportState, got %v", model.state)
+		}
 		view := model.View()
+		for _, want := range []string{"2 of 3 files failed", "rename: permission denied (2 files)", "- a.tmp", "- c.tmp"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
 
-		if !strings.Contains(view, "Error Occurred") {
-			t.Error("View should contain error title")
+		marked := model.batch.MarkedFiles()
+		if len(marked) != 2 {
+			t.Errorf("Expected failed files to stay marked, got %v", marked)
 		}
-		if !strings.Contains(view, "test error message") {
-			t.Error("View should contain error message")
+
+		model, _ = press(model, runes("x")...)
+		if model.state != EndState {
+			t.Errorf("Expected EndState with no pending files, got %v", model.state)
+		}
+		if len(model.failed) != 2 {
+			t.Errorf("Expected 2 failed files in session, got %d", len(model.failed))
+		}
+	})
+
+	t.Run("should quit once the bulk operation finished", func(t *testing.T) {
+		model, mockManager := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"})
+
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(10), nil).Times(2)
+		mockManager.EXPECT().Delete("a.tmp").Return(nil)
+		mockManager.EXPECT().Delete("b.tmp").Return(nil)
+
+		model, _ = press(model, runes("*tmp")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+		model, cmd := press(model, runes("d")...)
+		model, quitCmd := press(model, runes("q")...)
+
+		if model.state != BulkState || quitCmd != nil {
+			t.Fatalf("Expected bulk operation to keep running, got %v", model.state)
+		}
+		for model.bulk != nil {
+			model, cmd = press(model, cmd())
+		}
+
+		if model.Stats().Deleted != 2 {
+			t.Errorf("Expected both files recorded before quitting, got %+v", model.Stats())
+		}
+		if cmd == nil {
+			t.Fatal("Expected quit command")
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Error("Expected application to quit after the bulk operation")
+		}
+	})
+
+	t.Run("should skip all marked files", func(t *testing.T) {
+		model, mockManager := newModel(t, []string{"a.tmp", "b.tmp", "c.jpg"})
+
+		mockManager.EXPECT().Metadata("c.jpg").Return(domain.FileMeta{}, nil).AnyTimes()
+
+		model, _ = press(model, space, space)
+		model, _ = press(model, runes("s")...)
+
+		if model.Stats().Skipped != 2 {
+			t.Errorf("Expected 2 skipped files, got %d", model.Stats().Skipped)
+		}
+		if model.batch.CurrentFile() != "c.jpg" {
+			t.Errorf("Expected cursor on c.jpg, got %q", model.batch.CurrentFile())
+		}
+	})
+}
+
+func TestModel_Similar(t *testing.T) {
+	newModel := func(t *testing.T, files []string) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch(files)
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, WithPlain()), mockManager
+	}
+
+	press := func(m Model, msgs ...tea.Msg) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			var updated tea.Model
+			updated, cmd = m.Update(msg)
+			m = updated.(Model)
+		}
+		return m, cmd
+	}
+
+	runeKey := func(r rune) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+	}
+
+	t.Run("should ask about files with the same extension", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.log", "IMG_1.jpg", "b.log", "c.LOG"})
+
+		model, _ = press(model, runeKey('D'))
+
+		if model.state != ConfirmState {
+			t.Fatalf("Expected ConfirmState, got %v", model.state)
+		}
+		view := model.View()
+		for _, want := range []string{"Delete all 3 remaining files with extension .log?", "- b.log", "- c.LOG"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("View should contain %q, got %q", want, view)
+			}
+		}
+		if strings.Contains(view, "IMG_1.jpg") {
+			t.Error("View should not list files of other extensions")
+		}
+	})
+
+	t.Run("should switch to name prefix", func(t *testing.T) {
+		model, _ := newModel(t, []string{"IMG_1.jpg", "IMG_2.png", "DSC_3.jpg"})
+
+		model, _ = press(model, runeKey('K'), tea.KeyMsg{Type: tea.KeyTab})
+
+		files := model.similarFiles()
+		if len(files) != 2 || files[1] != "IMG_2.png" {
+			t.Errorf("Expected IMG_ files, got %v", files)
+		}
+		if !strings.Contains(model.View(), "Keep all 2 remaining files with prefix IMG_?") {
+			t.Errorf("View should ask about prefix, got %q", model.View())
+		}
+	})
+
+	t.Run("should return to manage state on no", func(t *testing.T) {
+		model, _ := newModel(t, []string{"a.log", "b.log"})
+
+		model, _ = press(model, runeKey('K'), runeKey('n'))
+
+		if model.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", model.state)
+		}
+	})
+
+	t.Run("should ignore files without traits", func(t *testing.T) {
+		model, _ := newModel(t, []string{"README"})
+
+		model, _ = press(model, runeKey('K'))
+
+		if model.state != FileManageState {
+			t.Errorf("Expected FileManageState, got %v", model.state)
+		}
+	})
+
+	t.Run("should skip decided files and process the rest", func(t *testing.T) {
+		model, mockManager := newModel(t, []string{"a.log", "b.log", "c.txt", "d.log"})
+
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(1), nil).Times(2)
+		mockManager.EXPECT().Delete("b.log").Return(nil)
+		mockManager.EXPECT().Delete("d.log").Return(nil)
+		mockManager.EXPECT().Metadata(gomock.Any()).Return(domain.FileMeta{}, nil).AnyTimes()
+
+		model, _ = press(model, runeKey('s'))
+		model, cmd := press(model, runeKey('D'), runeKey('y'))
+
+		for model.state == BulkState {
+			model, cmd = press(model, cmd())
+		}
+
+		if model.batch.StatusOf("a.log") != domain.StatusSkipped {
+			t.Errorf("Expected skipped a.log untouched, got %s", model.batch.StatusOf("a.log"))
+		}
+		if model.Stats().Deleted != 2 {
+			t.Errorf("Expected 2 deleted files, got %d", model.Stats().Deleted)
+		}
+		if model.batch.CurrentFile() != "c.txt" {
+			t.Errorf("Expected cursor on c.txt, got %q", model.batch.CurrentFile())
+		}
+	})
+}
+
+func TestModel_KeepModes(t *testing.T) {
+	newModel := func(t *testing.T, opts ...Option) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"file1.txt", "file2.txt"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, opts...), mockManager
+	}
+
+	run := func(t *testing.T, m Model, r rune) Model {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+		if m.queue.len() == 0 || cmd == nil {
+			t.Fatalf("Expected operation to start on %q", r)
+		}
+		return runQueue(m)
+	}
+
+	t.Run("should copy file on 'c' key", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		mockManager.EXPECT().Size("file1.txt").Return(int64(42), nil)
+		mockManager.EXPECT().Copy(gomock.Any(), "file1.txt").Return(nil)
+
+		model = run(t, model, 'c')
+
+		if model.batch.StatusOf("file1.txt") != domain.StatusKept {
+			t.Errorf("Expected copied file kept, got %s", model.batch.StatusOf("file1.txt"))
+		}
+		if model.Stats().BytesMoved != 0 {
+			t.Errorf("Copies should move nothing, got %d bytes", model.Stats().BytesMoved)
+		}
+	})
+
+	t.Run("should link files on 'L' and 'H' keys", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		mockManager.EXPECT().Symlink("file1.txt").Return(nil)
+		mockManager.EXPECT().Hardlink("file2.txt").Return(nil)
+
+		model = run(t, model, 'L')
+		model = run(t, model, 'H')
+
+		if model.state != EndState {
+			t.Errorf("Expected EndState, got %v", model.state)
+		}
+		if model.Stats().Kept != 2 || model.Stats().BytesMoved != 0 {
+			t.Errorf("Expected 2 linked files without bytes moved, got %+v", model.Stats())
+		}
+	})
+
+	t.Run("should run keep mode on 'k' key", func(t *testing.T) {
+		model, mockManager := newModel(t, WithKeepMode("symlink"))
+
+		mockManager.EXPECT().Symlink("file1.txt").Return(nil)
+
+		model = run(t, model, 'k')
+
+		if !strings.Contains(model.View(), "Keep (symlink)") {
+			t.Errorf("Options should show keep mode, got %q", model.View())
+		}
+	})
+
+	t.Run("should retry the failed action", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		gomock.InOrder(
+			mockManager.EXPECT().Hardlink("file1.txt").Return(errors.New("cross-device link")),
+			mockManager.EXPECT().Hardlink("file1.txt").Return(nil),
+		)
+
+		model = run(t, model, 'H')
+		if model.state != ErrorState {
+			t.Fatalf("Expected ErrorState, got %v", model.state)
+		}
+
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+		model = runQueue(updated.(Model))
+
+		if model.batch.StatusOf("file1.txt") != domain.StatusKept {
+			t.Error("Expected retried hard link to succeed")
+		}
+	})
+
+	t.Run("should ignore unknown keep mode", func(t *testing.T) {
+		model, _ := newModel(t, WithKeepMode("teleport"))
+
+		if model.keepAs != keepAction {
+			t.Errorf("Expected default keep action, got %v", model.keepAs)
+		}
+	})
+}
+
+func TestModel_Rename(t *testing.T) {
+	newModel := func(t *testing.T) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"IMG_2034.jpg", "IMG_2035.jpg"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, WithPlain()), mockManager
+	}
+
+	press := func(m Model, msgs ...tea.Msg) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			var updated tea.Model
+			updated, cmd = m.Update(msg)
+			m = updated.(Model)
+		}
+		return m, cmd
+	}
+
+	typed := func(text string) []tea.Msg {
+		var msgs []tea.Msg
+		for _, r := range text {
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return msgs
+	}
+
+	rename := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}
+	clearInput := tea.KeyMsg{Type: tea.KeyCtrlU}
+	mtime := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should prefill input with current name", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, rename)
+
+		if !model.naming || model.name.Value() != "IMG_2034.jpg" {
+			t.Errorf("Expected input prefilled with current name, got %q", model.name.Value())
+		}
+		if !strings.Contains(model.View(), "Rename to: ") {
+			t.Error("View should show rename input")
+		}
+	})
+
+	t.Run("should preview expanded template", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, MetaMsg{File: "IMG_2034.jpg", Meta: domain.FileMeta{ModTime: mtime, Parent: "trip"}})
+		model, _ = press(model, rename, clearInput)
+		model, _ = press(model, typed("{mtime}_{parent}_{counter:2}.{ext}")...)
+
+		if !strings.Contains(model.View(), "-> 2024-06-12_trip_01.jpg") {
+			t.Errorf("View should preview expanded name, got %q", model.View())
+		}
+	})
+
+	t.Run("should reject illegal names", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, rename, clearInput)
+		model, _ = press(model, typed("a/b")...)
+		model, cmd := press(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+		if cmd != nil || model.state != FileManageState || !model.naming {
+			t.Error("Illegal name should keep the input open")
+		}
+		if !strings.Contains(model.View(), "illegal character") {
+			t.Errorf("View should explain the error, got %q", model.View())
+		}
+	})
+
+	t.Run("should keep file under expanded name and count renames", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(3), nil).Times(2)
+		mockManager.EXPECT().Metadata(gomock.Any()).Return(domain.FileMeta{ModTime: mtime}, nil).Times(2)
+		mockManager.EXPECT().KeepAs(gomock.Any(), "IMG_2034.jpg", "beach-1.jpg").Return(nil)
+		mockManager.EXPECT().KeepAs(gomock.Any(), "IMG_2035.jpg", "beach-2.jpg").Return(nil)
+
+		for range 2 {
+			var cmd tea.Cmd
+			model, _ = press(model, rename, clearInput)
+			model, _ = press(model, typed("beach-{counter}.{ext}")...)
+			model, cmd = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+			if cmd == nil {
+				t.Fatal("Expected rename to start")
+			}
+		}
+		model = runQueue(model)
+
+		if model.state != EndState || model.Stats().Kept != 2 {
+			t.Errorf("Expected both files kept, got state %v and %+v", model.state, model.Stats())
+		}
+	})
+
+	t.Run("should show collision as error", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		mockManager.EXPECT().Size(gomock.Any()).Return(int64(0), nil)
+		mockManager.EXPECT().Metadata(gomock.Any()).Return(domain.FileMeta{}, nil)
+		mockManager.EXPECT().KeepAs(gomock.Any(), "IMG_2034.jpg", "IMG_2035.jpg").Return(errors.New("file already exists: IMG_2035.jpg"))
+
+		model, _ = press(model, rename, clearInput)
+		model, _ = press(model, typed("IMG_2035.jpg")...)
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyEnter})
+		model = runQueue(model)
+
+		if model.state != ErrorState || !strings.Contains(model.faults[0].err.Error(), "already exists") {
+			t.Errorf("Expected collision error, got state %v", model.state)
+		}
+	})
+
+	t.Run("should cancel on esc", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		model, _ = press(model, rename, tea.KeyMsg{Type: tea.KeyEsc})
+
+		if model.naming || model.state != FileManageState {
+			t.Error("Expected rename input closed")
 		}
 	})
 }
@@ -632,3 +2325,140 @@ func TestModel_CreateProgressBar(t *testing.T) {
 		}
 	})
 }
+
+func TestModel_Groups(t *testing.T) {
+	newModel := func(t *testing.T) (Model, *mocks.MockFileManager) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewGroupedBatch([][]string{{"DSC001.JPG", "DSC001.ARW", "DSC001.xmp"}, {"notes.txt"}})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		return InitialModel(batch, mockManager, WithPlain(), WithList()), mockManager
+	}
+
+	t.Run("should list the members of the current group", func(t *testing.T) {
+		model, _ := newModel(t)
+
+		view := model.View()
+
+		if !strings.Contains(view, "With DSC001.ARW, DSC001.xmp") {
+			t.Errorf("View should list group members, got %q", view)
+		}
+		if !strings.Contains(view, "DSC001.JPG +2") {
+			t.Errorf("List pane should show group size, got %q", view)
+		}
+	})
+
+	t.Run("should decide the group as one item", func(t *testing.T) {
+		model, mockManager := newModel(t)
+
+		mockManager.EXPECT().Size("DSC001.JPG").Return(int64(30), nil)
+		mockManager.EXPECT().Delete("DSC001.JPG").Return(nil)
+
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		model = runQueue(updated.(Model))
+
+		if model.batch.CurrentFile() != "notes.txt" {
+			t.Errorf("Expected next item notes.txt, got %s", model.batch.CurrentFile())
+		}
+		if strings.Contains(model.View(), "With ") {
+			t.Error("Ungrouped files should not list members")
+		}
+	})
+}
+
+func TestModel_Watch(t *testing.T) {
+	newModel := func(t *testing.T) (Model, *mocks.MockFileManager, chan [][]string) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockManager := mocks.NewMockFileManager(ctrl)
+		batch, err := domain.NewFileBatch([]string{"scan1.pdf"})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		files := make(chan [][]string, 1)
+		return InitialModel(batch, mockManager, WithPlain(), WithWatch(files)), mockManager, files
+	}
+
+	press := func(m Model, msgs ...tea.Msg) (Model, tea.Cmd) {
+		var cmd tea.Cmd
+		for _, msg := range msgs {
+			var updated tea.Model
+			updated, cmd = m.Update(msg)
+			m = updated.(Model)
+		}
+		return m, cmd
+	}
+
+	t.Run("should wait for files instead of ending", func(t *testing.T) {
+		model, mockManager, _ := newModel(t)
+
+		mockManager.EXPECT().Size("scan1.pdf").Return(int64(10), nil)
+		mockManager.EXPECT().Delete("scan1.pdf").Return(nil)
+
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		model = runQueue(model)
+
+		if model.state != WaitState {
+			t.Fatalf("Expected WaitState, got %v", model.state)
+		}
+		if !strings.Contains(model.View(), "Waiting for files") {
+			t.Errorf("Expected waiting screen, got %q", model.View())
+		}
+
+		if _, cmd := press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}); cmd != nil {
+			t.Error("Only quit should leave the waiting screen")
+		}
+		if _, cmd := press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}); cmd == nil {
+			t.Error("Expected quit command")
+		}
+	})
+
+	t.Run("should resume with new files", func(t *testing.T) {
+		model, mockManager, files := newModel(t)
+
+		mockManager.EXPECT().Size("scan1.pdf").Return(int64(10), nil)
+		mockManager.EXPECT().Keep(gomock.Any(), "scan1.pdf").Return(nil)
+
+		model, _ = press(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+		model = runQueue(model)
+
+		files <- [][]string{{"scan2.pdf"}}
+		msg := model.waitFiles()()
+		if _, ok := msg.(FilesMsg); !ok {
+			t.Fatalf("Expected FilesMsg, got %T", msg)
+		}
+
+		model, cmd := press(model, msg)
+		if model.state != FileManageState || model.batch.CurrentFile() != "scan2.pdf" {
+			t.Errorf("Expected to manage scan2.pdf, got state %v file %q", model.state, model.batch.CurrentFile())
+		}
+		if cmd == nil {
+			t.Error("Expected commands waiting for files and loading metadata")
+		}
+	})
+
+	t.Run("should start waiting with no files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		model := InitialModel(domain.NewOpenBatch(nil), mocks.NewMockFileManager(ctrl), WithWatch(make(chan [][]string)))
+
+		if model.state != WaitState {
+			t.Errorf("Expected WaitState, got %v", model.state)
+		}
+	})
+
+	t.Run("should stop waiting when the watcher closes", func(t *testing.T) {
+		model, _, files := newModel(t)
+		close(files)
+
+		if msg := model.waitFiles()(); msg != nil {
+			t.Errorf("Expected no message, got %v", msg)
+		}
+	})
+}
@@ -3,23 +3,50 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rycln/filer/internal/domain"
 )
 
+// Below this terminal size the view collapses to a single line.
+const (
+	minWidth  = 40
+	minHeight = 10
+)
+
+// defaultBarWidth is used until the terminal size is known.
+const defaultBarWidth = 30
+
+const timeLayout = "2006-01-02 15:04"
+
 func (m Model) View() string {
+	if m.compact() {
+		return m.compactView()
+	}
+
 	var s strings.Builder
 	s.WriteString("\n")
 
 	switch m.state {
 	case FileManageState:
-		s.WriteString(m.fileManageView())
+		s.WriteString(m.withList(Model.fileManageView))
 	case ProcessingState:
 		s.WriteString(m.processingView())
 	case EndState:
 		s.WriteString(m.endView())
 	case ErrorState:
 		s.WriteString(m.errorView())
+	case BulkState:
+		s.WriteString(m.withList(Model.bulkView))
+	case ReportState:
+		s.WriteString(m.reportView())
+	case ConfirmState:
+		s.WriteString(m.confirmView())
+	case WaitState:
+		s.WriteString(m.waitView())
 	}
 
 	return s.String()
@@ -28,46 +55,475 @@ func (m Model) View() string {
 func (m Model) fileManageView() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render("📁 File Manager"))
+	s.WriteString(m.styles.title.Render(m.glyphs.manage + "File Manager"))
 	s.WriteString("\n")
 
 	progress := m.createProgressBar(m.batch.Progress(), m.batch.TotalFiles())
 	s.WriteString(progress)
 	s.WriteString("\n\n")
 
-	currentFile := fmt.Sprintf("📄 %s", m.batch.CurrentFile())
-	s.WriteString(fileStyle.Render(currentFile))
+	if space := m.spaceView(); space != "" {
+		s.WriteString(space)
+		s.WriteString("\n\n")
+	}
+
+	if m.querying || m.batch.Filtered() {
+		s.WriteString(m.filterView())
+		s.WriteString("\n\n")
+	}
+
+	if marks := m.marksView(); marks != "" {
+		s.WriteString(marks)
+		s.WriteString("\n\n")
+	}
+
+	if m.batch.CurrentFile() == "" {
+		s.WriteString(m.styles.label.Render("No files match the filter"))
+		s.WriteString("\n\n")
+		s.WriteString(m.actionsView(m.manageActions()...))
+		return s.String()
+	}
+
+	currentFile := m.fileLine(m.batch.CurrentFile())
+	s.WriteString(m.styles.file.Render(currentFile))
 	s.WriteString("\n\n")
 
-	options := []string{
-		optionStyle.Render("K") + "eep",
-		optionStyle.Render("D") + "elete",
-		optionStyle.Render("S") + "kip",
-		optionStyle.Render("Q") + "uit",
+	if group := m.groupView(); group != "" {
+		s.WriteString(group)
+		s.WriteString("\n\n")
 	}
-	optionsLine := strings.Join(options, " "+dividerStyle.String()+" ")
 
-	s.WriteString("❓ Action: ")
-	s.WriteString(optionsLine)
+	if m.naming {
+		s.WriteString(m.nameView())
+		s.WriteString("\n\n")
+	}
+
+	if status := m.batch.CurrentStatus(); status != domain.StatusPending {
+		s.WriteString(m.styles.statusStyle(status).Render("Already " + status.String()))
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.metaView())
+	s.WriteString("\n\n")
+
+	if queue := m.queueView(); queue != "" {
+		s.WriteString(queue)
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.actionsView(m.manageActions()...))
 
 	return s.String()
 }
 
+// manageActions returns the bindings offered in the manage state,
+// which are the filter input keys while it has focus.
+func (m Model) manageActions() []key.Binding {
+	if m.naming {
+		apply, cancel := m.keys.Apply, m.keys.Clear
+		apply.SetHelp(apply.Help().Key, "rename")
+		cancel.SetHelp(cancel.Help().Key, "cancel")
+		return []key.Binding{apply, cancel}
+	}
+	if m.querying || m.marking {
+		return m.keys.filterBindings()
+	}
+	return m.keys.ShortHelp()
+}
+
+// nameView renders the rename input with a preview of the expanded name.
+func (m Model) nameView() string {
+	line := m.styles.label.Render("Rename to: ") + m.name.View()
+
+	if m.nameErr != nil {
+		return line + "\n" + m.styles.err.Render(m.nameErr.Error())
+	}
+
+	name, _ := m.expandName(m.batch.CurrentFile(), m.currentMeta())
+	if name != m.newName {
+		line += "\n" + m.styles.label.Render(m.glyphs.link+" ") + m.styles.text.Render(name)
+	}
+	return line
+}
+
+// filterView renders the filter query with its mode and match state.
+func (m Model) filterView() string {
+	mode := "fuzzy"
+	if m.regex {
+		mode = "regex"
+	}
+	label := m.styles.label.Render(fmt.Sprintf("Filter (%s): ", mode))
+
+	query := m.styles.text.Render(m.query.Value())
+	if m.querying {
+		query = m.query.View()
+	}
+	line := label + query
+
+	switch {
+	case m.queryErr != nil:
+		line += "\n" + m.styles.err.Render("Invalid pattern: "+m.queryErr.Error())
+	case m.batch.TotalFiles() > 0 && m.batch.Progress() == m.batch.TotalFiles():
+		line += "\n" + m.styles.label.Render("No pending files match the filter")
+	}
+
+	return line
+}
+
+// marksView renders the mark pattern input or the selection size.
+func (m Model) marksView() string {
+	mode := "fuzzy"
+	if m.regex {
+		mode = "regex"
+	}
+
+	switch {
+	case m.marking:
+		line := m.styles.label.Render(fmt.Sprintf("Mark (%s): ", mode)) + m.pattern.View()
+		if m.matchErr != nil {
+			line += "\n" + m.styles.err.Render("Invalid pattern: "+m.matchErr.Error())
+		}
+		return line
+	case m.visual:
+		from, to := m.visualRange()
+		return m.styles.cursor.Render("Selecting " + plural(to-from+1, "file"))
+	}
+
+	if n := len(m.batch.MarkedFiles()); n > 0 {
+		return m.styles.cursor.Render(plural(n, "file") + " marked, actions apply to all of them")
+	}
+	return ""
+}
+
+// listPaneWidth bounds the width of the file list pane.
+const (
+	minListPaneWidth = 20
+	maxListPaneWidth = 40
+)
+
+// withList renders a state view with the file list pane when it is shown.
+// The pane goes to the right on wide terminals and below otherwise.
+func (m Model) withList(view func(Model) string) string {
+	if !m.showList {
+		return view(m)
+	}
+
+	paneWidth := maxListPaneWidth
+	if m.width > 0 {
+		paneWidth = max(minListPaneWidth, min(m.width/3, maxListPaneWidth))
+	}
+
+	if m.plain || (m.width > 0 && m.width-paneWidth < 2*minWidth) {
+		return view(m) + "\n\n" + m.listView(paneWidth)
+	}
+
+	main := m
+	if m.width > 0 {
+		main.width = m.width - paneWidth - 3
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, view(main), "   ", m.listView(paneWidth))
+}
+
+// listView renders files around the cursor marked by their status.
+func (m Model) listView(width int) string {
+	rows := 10
+	if m.height > 0 {
+		rows = max(m.height-6, 3)
+	}
+
+	total := m.batch.TotalFiles()
+	cursor := min(m.batch.Cursor(), total-1)
+	start := max(0, min(cursor-rows/2, total-rows))
+	end := min(start+rows, total)
+
+	from, to := -1, -1
+	if m.visual {
+		from, to = m.visualRange()
+	}
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		status := m.batch.Status(i)
+		pointer := strings.Repeat(" ", runewidth.StringWidth(m.glyphs.cursor))
+		if i == cursor {
+			pointer = m.styles.cursor.Render(m.glyphs.cursor)
+		}
+		mark := strings.Repeat(" ", runewidth.StringWidth(m.glyphs.mark))
+		if m.batch.IsMarked(i) || (i >= from && i <= to) {
+			mark = m.styles.cursor.Render(m.glyphs.mark)
+		}
+		prefix := pointer + mark
+
+		marker := m.glyphs.statuses[status] + " "
+		nameWidth := width - runewidth.StringWidth(m.glyphs.cursor+m.glyphs.mark) - runewidth.StringWidth(marker)
+		var group string
+		if n := len(m.batch.Members(m.batch.File(i))); n > 1 {
+			group = fmt.Sprintf(" +%d", n-1)
+		}
+		name := truncateMiddle(m.batch.File(i), nameWidth-len(group), m.glyphs.ellipsis) + group
+
+		lines = append(lines, prefix+m.styles.statusStyle(status).Render(marker+name))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// groupView lists the other files of the current group.
+// Empty for files that are not grouped.
+func (m Model) groupView() string {
+	members := m.batch.Members(m.batch.CurrentFile())
+	if len(members) < 2 {
+		return ""
+	}
+
+	line := strings.Join(members[1:], ", ")
+	if m.width > 0 {
+		line = truncateMiddle(line, m.width-len("With ")-1, m.glyphs.ellipsis)
+	}
+	return m.styles.label.Render("With ") + m.styles.text.Render(line)
+}
+
+// spaceView renders the room left in the target directory and warns
+// when the current file does not fit.
+func (m Model) spaceView() string {
+	if !m.space.Known() {
+		return ""
+	}
+
+	var parts []string
+	if m.space.Free >= 0 {
+		parts = append(parts, formatBytes(m.space.Free)+" free")
+	}
+	if m.space.Quota >= 0 {
+		parts = append(parts, formatBytes(m.space.Quota)+" of quota left")
+	}
+	view := m.styles.label.Render("Target: " + strings.Join(parts, ", "))
+
+	loaded, ok := m.metas[m.batch.CurrentFile()]
+	if ok && loaded.Err == nil && !m.space.Fits(loaded.Meta.Size) {
+		view += "\n" + m.styles.errText.Render(fmt.Sprintf("%s does not fit into the target", formatBytes(loaded.Meta.Size)))
+	}
+	return view
+}
+
+// metaView renders the metadata panel of the current file.
+func (m Model) metaView() string {
+	loaded, ok := m.metas[m.batch.CurrentFile()]
+	if !ok {
+		return m.styles.label.Render("Loading metadata...")
+	}
+	if loaded.Err != nil {
+		return m.styles.label.Render("Metadata unavailable: " + loaded.Err.Error())
+	}
+
+	meta := loaded.Meta
+	now := time.Now()
+
+	rows := [][2]string{
+		{"Size", formatBytes(meta.Size)},
+		{"Modified", fmt.Sprintf("%s (%s)", formatRelative(meta.ModTime, now), meta.ModTime.Format(timeLayout))},
+	}
+	if !meta.BirthTime.IsZero() {
+		rows = append(rows, [2]string{"Created", fmt.Sprintf("%s (%s)", formatRelative(meta.BirthTime, now), meta.BirthTime.Format(timeLayout))})
+	}
+	rows = append(rows, [2]string{"Mode", meta.Mode.String()})
+	if meta.Owner != "" {
+		rows = append(rows, [2]string{"Owner", meta.Owner + ":" + meta.Group})
+	}
+	if meta.Links > 0 {
+		rows = append(rows, [2]string{"Links", fmt.Sprint(meta.Links)})
+	}
+	if meta.Symlink {
+		rows = append(rows, [2]string{"Symlink", m.glyphs.link + " " + meta.LinkTarget})
+	}
+	rows = append(rows, imageRows(meta)...)
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		label := m.styles.label.Render(fmt.Sprintf("%-9s", row[0]))
+		lines = append(lines, label+" "+m.styles.text.Render(row[1]))
+	}
+
+	panel := m.styles.panel
+	if m.width > 0 {
+		panel = panel.Width(m.width - panel.GetHorizontalBorderSize() - panel.GetHorizontalMargins())
+	}
+	return panel.Render(strings.Join(lines, "\n"))
+}
+
+// imageRows renders EXIF fields of images for the metadata panel.
+func imageRows(meta domain.FileMeta) [][2]string {
+	var rows [][2]string
+	if meta.Camera != "" {
+		rows = append(rows, [2]string{"Camera", meta.Camera})
+	}
+	if !meta.Taken.IsZero() {
+		rows = append(rows, [2]string{"Taken", meta.Taken.Format(timeLayout)})
+	}
+	if meta.Width > 0 {
+		image := fmt.Sprintf("%dx%d", meta.Width, meta.Height)
+		if meta.Rotated {
+			image += " (rotated)"
+		}
+		rows = append(rows, [2]string{"Image", image})
+	}
+	if meta.IsImage() {
+		gps := "no"
+		if meta.GPS {
+			gps = "yes"
+		}
+		rows = append(rows, [2]string{"GPS", gps})
+	}
+	return rows
+}
+
+// processingView shows the operations left before the session ends.
 func (m Model) processingView() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render("⚙️  Processing Files"))
+	s.WriteString(m.styles.title.Render(m.glyphs.processing + "Processing Files"))
 	s.WriteString("\n")
 
 	progress := m.createProgressBar(m.batch.Progress(), m.batch.TotalFiles())
 	s.WriteString(progress)
 	s.WriteString("\n\n")
 
-	currentFile := fmt.Sprintf("📄 %s", m.batch.CurrentFile())
-	s.WriteString(fileStyle.Render(currentFile))
+	s.WriteString(m.queueView())
+	s.WriteString("\n\n")
+
+	s.WriteString(m.actionsView(m.keys.Quit, m.keys.Help))
+
+	return s.String()
+}
+
+// queueView shows how many operations are queued and what the running
+// ones are doing. Empty when the queue is.
+func (m Model) queueView() string {
+	ops := m.queue.snapshot()
+	if len(ops) == 0 {
+		return ""
+	}
+
+	var running []operation
+	for _, op := range ops {
+		if !op.started.IsZero() {
+			running = append(running, op)
+		}
+	}
+
+	summary := fmt.Sprintf("%s%d running, %d queued", m.glyphs.wait, len(running), len(ops)-len(running))
+	switch {
+	case m.ctx.Err() != nil:
+		summary += ", cancelling"
+	case m.quitting:
+		summary += ", exiting when done"
+	}
+
+	var s strings.Builder
+	s.WriteString(m.styles.processing.Render(summary))
+	for _, op := range running {
+		elapsed := time.Since(op.started)
+		line := fmt.Sprintf("  %s %s (%s)", op.act.progressive(), op.file, elapsed.Truncate(time.Second))
+		if p, ok := m.copies[op.file]; ok {
+			line += ": " + formatTransfer(p.Copied, p.Total, elapsed)
+		}
+		s.WriteString("\n")
+		s.WriteString(m.styles.label.Render(line))
+	}
+
+	return s.String()
+}
+
+// confirmPreview limits the files listed when asking about similar files.
+const confirmPreview = 5
+
+// confirmView asks to apply the action to all similar files.
+func (m Model) confirmView() string {
+	var s strings.Builder
+
+	files := m.similarFiles()
+	question := fmt.Sprintf("%s%s all %s with %s?", m.glyphs.action, m.action, plural(len(files), "remaining file"), m.sims[m.sim].label)
+	s.WriteString(m.styles.title.Render(question))
+	s.WriteString("\n\n")
+
+	for _, f := range files[:min(len(files), confirmPreview)] {
+		s.WriteString(fmt.Sprintf("  %s %s\n", m.glyphs.bullet, f))
+	}
+	if more := len(files) - confirmPreview; more > 0 {
+		s.WriteString(m.styles.label.Render(fmt.Sprintf("  and %d more", more)))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	bindings := []key.Binding{m.keys.Yes, m.keys.No}
+	if len(m.sims) > 1 {
+		labels := make([]string, len(m.sims))
+		for i, sim := range m.sims {
+			labels[i] = sim.label
+			if i == m.sim {
+				labels[i] = m.styles.option.Render("[" + sim.label + "]")
+			}
+		}
+		s.WriteString(m.styles.label.Render("Match by: ") + strings.Join(labels, " "))
+		s.WriteString("\n\n")
+		bindings = append(bindings, m.keys.Switch)
+	}
+
+	s.WriteString(m.actionsView(bindings...))
+
+	return s.String()
+}
+
+// bulkView shows the aggregate progress of an operation on many files.
+func (m Model) bulkView() string {
+	var s strings.Builder
+
+	title := fmt.Sprintf("%s%s %s", m.glyphs.processing, m.action.progressive(), plural(m.bulkAll, "file"))
+	s.WriteString(m.styles.title.Render(title))
+	s.WriteString("\n")
+
+	s.WriteString(m.createProgressBar(m.bulkDone, m.bulkAll))
+	s.WriteString("\n\n")
+
+	if n := len(m.bulkErrs); n > 0 {
+		s.WriteString(m.styles.err.Render(m.glyphs.failed + plural(n, "file") + " failed"))
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.styles.processing.Render(m.glyphs.wait + "Processing..."))
+
+	return s.String()
+}
+
+// reportView lists the failures of a bulk operation grouped by error kind.
+func (m Model) reportView() string {
+	var s strings.Builder
+
+	summary := fmt.Sprintf("%s%d of %d files failed", m.glyphs.err, len(m.bulkErrs), m.bulkAll)
+	s.WriteString(m.styles.err.Render(summary))
 	s.WriteString("\n\n")
 
-	s.WriteString(processingStyle.Render("⏳ Processing..."))
+	var kinds []string
+	byKind := make(map[string][]failedFile)
+	for _, f := range m.bulkErrs {
+		if _, ok := byKind[f.kind]; !ok {
+			kinds = append(kinds, f.kind)
+		}
+		byKind[f.kind] = append(byKind[f.kind], f)
+	}
+
+	for _, kind := range kinds {
+		files := byKind[kind]
+		header := fmt.Sprintf("%s (%s)", kind, plural(len(files), "file"))
+		s.WriteString(m.styles.errText.Render(header))
+		s.WriteString("\n")
+		for _, f := range files {
+			s.WriteString(fmt.Sprintf("  %s %s\n", m.glyphs.bullet, f.name))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(m.glyphs.exit + "Press any key to continue")
 
 	return s.String()
 }
@@ -75,14 +531,73 @@ func (m Model) processingView() string {
 func (m Model) endView() string {
 	var s strings.Builder
 
-	s.WriteString(successStyle.Render("🎉 Processing Complete!"))
+	s.WriteString(m.styles.success.Render(m.glyphs.done + "Processing Complete!"))
 	s.WriteString("\n\n")
 
-	stats := fmt.Sprintf("✅ Processed %d files", m.batch.TotalFiles())
-	s.WriteString(progressStyle.Render(stats))
+	stats := fmt.Sprintf("%sProcessed %d of %d files", m.glyphs.stats, m.stats.Total(), m.batch.TotalFiles())
+	s.WriteString(m.styles.progress.Render(stats))
+	s.WriteString("\n")
+	s.WriteString(m.statsView())
+	s.WriteString("\n")
+
+	if len(m.failed) > 0 {
+		s.WriteString(m.styles.err.Render(fmt.Sprintf("%sFailed %d files", m.glyphs.failed, len(m.failed))))
+		s.WriteString("\n")
+		for _, f := range m.failed {
+			s.WriteString(fmt.Sprintf("  %s %s: %s\n", m.glyphs.bullet, f.name, f.err))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString(m.glyphs.exit + "Press any key to exit")
+
+	return s.String()
+}
+
+func (m Model) waitView() string {
+	var s strings.Builder
+
+	s.WriteString(m.styles.title.Render(m.glyphs.wait + "Waiting for files"))
 	s.WriteString("\n\n")
 
-	s.WriteString("👆 Press any key to exit")
+	stats := fmt.Sprintf("%sProcessed %d of %d files, new files appear here", m.glyphs.stats, m.stats.Total(), m.batch.TotalFiles())
+	s.WriteString(m.styles.progress.Render(stats))
+	s.WriteString("\n")
+	s.WriteString(m.statsView())
+	s.WriteString("\n")
+
+	if queue := m.queueView(); queue != "" {
+		s.WriteString(queue)
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.actionsView(m.keys.Help, m.keys.Quit))
+
+	return s.String()
+}
+
+func (m Model) statsView() string {
+	var s strings.Builder
+
+	st := m.stats
+	s.WriteString(fmt.Sprintf("  Kept:    %d (%s moved)\n", st.Kept, formatBytes(st.BytesMoved)))
+	s.WriteString(fmt.Sprintf("  Deleted: %d (%s freed)\n", st.Deleted, formatBytes(st.BytesFreed)))
+	s.WriteString(fmt.Sprintf("  Skipped: %d\n", st.Skipped))
+	s.WriteString(fmt.Sprintf("  Failed:  %d\n", st.Failed))
+	s.WriteString(fmt.Sprintf("  Elapsed: %s (%s per decision)\n",
+		formatDuration(st.Elapsed()), formatDuration(st.AverageDecision())))
+
+	exts := st.Extensions()
+	if len(exts) == 0 {
+		return s.String()
+	}
+
+	s.WriteString("\n")
+	s.WriteString(fmt.Sprintf("  %-10s %6s %8s %8s %7s\n", "Extension", "Kept", "Deleted", "Skipped", "Failed"))
+	for _, ext := range exts {
+		c := st.ByExtension(ext)
+		s.WriteString(fmt.Sprintf("  %-10s %6d %8d %8d %7d\n", ext, c.Kept, c.Deleted, c.Skipped, c.Failed))
+	}
 
 	return s.String()
 }
@@ -90,51 +605,171 @@ func (m Model) endView() string {
 func (m Model) errorView() string {
 	var s strings.Builder
 
-	s.WriteString(errorStyle.Render("❌ Error Occurred"))
+	f := m.faults[0]
+	s.WriteString(m.styles.err.Render(m.glyphs.err + "Error Occurred"))
 	s.WriteString("\n\n")
 
-	errorMsg := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("203")).
-		BorderLeft(true).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("196")).
-		PaddingLeft(1).
-		Render(m.errMsg)
+	failedFile := m.fileLine(f.op.file)
+	s.WriteString(m.styles.file.Render(failedFile))
+	s.WriteString("\n\n")
+
+	errStyle := m.styles.errText
+	if m.width > 0 {
+		errStyle = errStyle.Width(m.width - errStyle.GetHorizontalFrameSize())
+	}
+	errorMsg := errStyle.Render(f.err.Error())
 
 	s.WriteString(errorMsg)
 	s.WriteString("\n\n")
 
-	s.WriteString("👆 Press any key to exit")
+	if more := len(m.faults) - 1; more > 0 {
+		s.WriteString(m.styles.label.Render(plural(more, "other file") + " failed"))
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(m.actionsView(m.keys.Retry, m.keys.Skip, m.keys.Ignore, m.keys.Quit, m.keys.Help))
 
 	return s.String()
 }
 
+// actionsView renders the options line for the given bindings,
+// or the full help overlay when it is toggled on.
+func (m Model) actionsView(bindings ...key.Binding) string {
+	if m.showHelp {
+		return m.helpView()
+	}
+
+	options := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		options = append(options, m.optionLabel(b))
+	}
+	optionsLine := strings.Join(options, " "+m.styles.divider.String()+" ")
+
+	return m.glyphs.action + "Action: " + optionsLine
+}
+
+// optionLabel highlights the key inside the action name when it is the
+// first letter ("Keep" for k), otherwise shows the key before the name.
+func (m Model) optionLabel(b key.Binding) string {
+	h := b.Help()
+	if h.Desc == "" {
+		return m.styles.option.Render(h.Key)
+	}
+
+	first, rest := h.Desc[:1], h.Desc[1:]
+	if m.plain {
+		return "[" + h.Key + "] " + strings.ToUpper(first) + rest
+	}
+
+	if strings.EqualFold(h.Key, first) {
+		return m.styles.option.Render(strings.ToUpper(first)) + rest
+	}
+
+	return m.styles.option.Render(h.Key) + " " + strings.ToUpper(first) + rest
+}
+
+// helpView renders all bindings, one per line in plain mode.
+func (m Model) helpView() string {
+	if !m.plain {
+		return m.help.FullHelpView(m.keys.FullHelp())
+	}
+
+	var lines []string
+	for _, group := range m.keys.FullHelp() {
+		for _, b := range group {
+			lines = append(lines, fmt.Sprintf("%s: %s", b.Help().Key, b.Help().Desc))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m Model) createProgressBar(current, total int) string {
 	if total == 0 {
 		return ""
 	}
 
 	percentage := float64(current) / float64(total)
-	width := 30
+	progressText := fmt.Sprintf(" %d/%d (%.1f%%)", current, total, percentage*100)
+
+	width := defaultBarWidth
+	if m.width > 0 {
+		width = max(m.width-runewidth.StringWidth(progressText)-2, 10)
+	}
 	filled := int(percentage * float64(width))
 	empty := width - filled
 
-	filledBar := strings.Repeat("█", filled)
-	emptyBar := strings.Repeat("░", empty)
-
-	filledStyled := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("46")).
-		Render(filledBar)
+	filledBar := strings.Repeat(m.glyphs.barFilled, filled)
+	emptyBar := strings.Repeat(m.glyphs.barEmpty, empty)
 
-	emptyStyled := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
-		Render(emptyBar)
+	filledStyled := m.styles.barFilled.Render(filledBar)
+	emptyStyled := m.styles.barEmpty.Render(emptyBar)
 
-	progressText := fmt.Sprintf(" %d/%d (%.1f%%)", current, total, percentage*100)
+	progressTextStyled := m.styles.text.Render(progressText)
 
-	progressTextStyled := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
-		Render(progressText)
+	if m.plain {
+		return "[" + filledStyled + emptyStyled + "]" + progressTextStyled
+	}
 
 	return filledStyled + emptyStyled + progressTextStyled
 }
+
+// fileLine renders the file glyph and name, truncated to the window width.
+func (m Model) fileLine(filename string) string {
+	if m.width <= 0 {
+		return m.glyphs.file + filename
+	}
+
+	width := m.width - runewidth.StringWidth(m.glyphs.file) - 1
+	return m.glyphs.file + truncateMiddle(filename, width, m.glyphs.ellipsis)
+}
+
+func (m Model) compact() bool {
+	if m.width <= 0 || m.height <= 0 {
+		return false
+	}
+	return m.width < minWidth || m.height < minHeight
+}
+
+// compactView renders the whole state as a single unstyled line.
+func (m Model) compactView() string {
+	progress := fmt.Sprintf("%d/%d", m.batch.Progress(), m.batch.TotalFiles())
+
+	var keys []string
+	var line string
+
+	switch m.state {
+	case FileManageState:
+		for _, b := range m.manageActions() {
+			keys = append(keys, b.Help().Key)
+		}
+		line = progress + " " + m.batch.CurrentFile()
+	case ProcessingState:
+		line = fmt.Sprintf("finishing %s", plural(m.queue.len(), "operation"))
+		keys = []string{m.keys.Quit.Help().Key}
+	case EndState:
+		line = fmt.Sprintf("done %d/%d", m.stats.Total(), m.batch.TotalFiles())
+		keys = []string{"any key"}
+	case ErrorState:
+		for _, b := range []key.Binding{m.keys.Retry, m.keys.Skip, m.keys.Ignore, m.keys.Quit} {
+			keys = append(keys, b.Help().Key)
+		}
+		line = "error: " + m.faults[0].err.Error()
+	case BulkState:
+		line = fmt.Sprintf("processing %d/%d", m.bulkDone, m.bulkAll)
+		keys = []string{"..."}
+	case ReportState:
+		line = fmt.Sprintf("%d of %d files failed", len(m.bulkErrs), m.bulkAll)
+		keys = []string{"any key"}
+	case WaitState:
+		line = fmt.Sprintf("waiting %d/%d", m.stats.Total(), m.batch.TotalFiles())
+		keys = []string{m.keys.Quit.Help().Key}
+	case ConfirmState:
+		line = fmt.Sprintf("all %s with %s?", plural(len(m.similarFiles()), "file"), m.sims[m.sim].label)
+		keys = []string{m.keys.Yes.Help().Key, m.keys.No.Help().Key}
+	}
+
+	suffix := " [" + strings.Join(keys, " ") + "]"
+	width := m.width - runewidth.StringWidth(suffix)
+
+	return truncateMiddle(line, width, m.glyphs.ellipsis) + suffix
+}
@@ -0,0 +1,138 @@
+// Package watcher reports files arriving in a directory.
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher reports new files in a directory once they stop changing.
+// Files that settle together are reported in one slice so related
+// files, like RAW and JPEG pairs, arrive together.
+type Watcher struct {
+	dir    string
+	settle time.Duration
+	fsw    *fsnotify.Watcher
+	files  chan []string
+	done   chan struct{}
+	once   sync.Once
+}
+
+// maxWaitFactor bounds how many settle periods a settled file waits for
+// other files to finish before it is reported anyway.
+const maxWaitFactor = 10
+
+// New starts watching dir. A file is reported after no change for settle.
+func New(dir string, settle time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(dir); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		dir:    filepath.Clean(dir),
+		settle: settle,
+		fsw:    fsw,
+		files:  make(chan []string),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Files returns the channel new file names are sent on.
+// Closed when the watcher is closed.
+func (w *Watcher) Files() <-chan []string {
+	return w.files
+}
+
+// Close stops watching. Safe to call more than once.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+	})
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.files)
+
+	busy := make(map[string]time.Time) // last change of files being written
+	var ready []string
+	var readySince time.Time
+
+	tick := time.NewTicker(max(w.settle/4, time.Millisecond))
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case _, ok := <-w.fsw.Errors:
+			// Errors like event queue overflow only lose events.
+			if !ok {
+				return
+			}
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if filepath.Dir(ev.Name) != w.dir {
+				continue
+			}
+			name := filepath.Base(ev.Name)
+			switch {
+			case ev.Has(fsnotify.Create), ev.Has(fsnotify.Write), ev.Has(fsnotify.Chmod):
+				busy[name] = time.Now()
+			case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+				delete(busy, name)
+				ready = slices.DeleteFunc(ready, func(r string) bool { return r == name })
+			}
+		case now := <-tick.C:
+			for name, changed := range busy {
+				if now.Sub(changed) < w.settle {
+					continue
+				}
+				delete(busy, name)
+				if !w.isFile(name) || slices.Contains(ready, name) {
+					continue
+				}
+				if len(ready) == 0 {
+					readySince = now
+				}
+				ready = append(ready, name)
+			}
+
+			if len(ready) == 0 || (len(busy) > 0 && now.Sub(readySince) < maxWaitFactor*w.settle) {
+				continue
+			}
+
+			slices.Sort(ready)
+			select {
+			case w.files <- ready:
+				ready = nil
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// isFile reports whether name is a regular file or symlink in the
+// watched directory.
+func (w *Watcher) isFile(name string) bool {
+	info, err := os.Lstat(filepath.Join(w.dir, name))
+	return err == nil && !info.IsDir()
+}
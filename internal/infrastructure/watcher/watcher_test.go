@@ -0,0 +1,104 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	const settle = 50 * time.Millisecond
+
+	receive := func(t *testing.T, w *Watcher) []string {
+		t.Helper()
+		select {
+		case files := <-w.Files():
+			return files
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for files")
+			return nil
+		}
+	}
+
+	setup := func(t *testing.T) (*Watcher, string) {
+		dir := t.TempDir()
+		w, err := New(dir, settle)
+		if err != nil {
+			t.Fatalf("Failed to create watcher: %v", err)
+		}
+		t.Cleanup(func() { w.Close() })
+		return w, dir
+	}
+
+	t.Run("should report new files together once written", func(t *testing.T) {
+		w, dir := setup(t)
+
+		for _, name := range []string{"scan.jpg", "scan.arw"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+		}
+
+		if got := receive(t, w); !slices.Equal(got, []string{"scan.arw", "scan.jpg"}) {
+			t.Errorf("Expected both files, got %v", got)
+		}
+	})
+
+	t.Run("should wait for files still being written", func(t *testing.T) {
+		w, dir := setup(t)
+
+		f, err := os.Create(filepath.Join(dir, "big.pdf"))
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		start := time.Now()
+		for range 6 {
+			f.Write([]byte("chunk"))
+			time.Sleep(settle / 2)
+		}
+		f.Close()
+
+		got := receive(t, w)
+		if !slices.Equal(got, []string{"big.pdf"}) {
+			t.Errorf("Expected big.pdf, got %v", got)
+		}
+		if time.Since(start) < 3*settle {
+			t.Error("File was reported while still being written")
+		}
+	})
+
+	t.Run("should ignore directories and removed files", func(t *testing.T) {
+		w, dir := setup(t)
+
+		if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		tmp := filepath.Join(dir, "partial.tmp")
+		if err := os.WriteFile(tmp, nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, "done.txt")); err != nil {
+			t.Fatalf("Failed to rename file: %v", err)
+		}
+
+		if got := receive(t, w); !slices.Equal(got, []string{"done.txt"}) {
+			t.Errorf("Expected only done.txt, got %v", got)
+		}
+	})
+
+	t.Run("should close files channel on close", func(t *testing.T) {
+		w, _ := setup(t)
+		w.Close()
+
+		select {
+		case _, ok := <-w.Files():
+			if ok {
+				t.Error("Expected closed channel")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for close")
+		}
+	})
+}
@@ -1,26 +1,175 @@
 package usecases
 
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/rycln/filer/internal/domain"
+)
+
 //go:generate mockgen -source=$GOFILE -destination=./mocks/mock_$GOFILE -package=mocks
 
 type FileSystem interface {
-	KeepFile(string) error
+	KeepFile(context.Context, string) error
+	KeepFileAs(context.Context, string, string) error
+	CopyFile(context.Context, string) error
+	SymlinkFile(string) error
+	HardlinkFile(string) error
+	Restore(string) error
 	DeleteFile(string) error
+	FileSize(string) (int64, error)
+	Metadata(string) (domain.FileMeta, error)
+	TargetSpace() (domain.TargetSpace, error)
 }
 
 type FileProcessor struct {
-	fs FileSystem
+	fs      FileSystem
+	members func(string) []string
+}
+
+// Option customizes a FileProcessor.
+type Option func(*FileProcessor)
+
+// WithGroups makes operations act on every file of a group.
+// members returns the files of the group named after filename.
+func WithGroups(members func(filename string) []string) Option {
+	return func(p *FileProcessor) {
+		p.members = members
+	}
 }
 
-func NewFileProcessor(fs FileSystem) *FileProcessor {
-	return &FileProcessor{
-		fs: fs,
+func NewFileProcessor(fs FileSystem, opts ...Option) *FileProcessor {
+	p := &FileProcessor{
+		fs:      fs,
+		members: func(filename string) []string { return []string{filename} },
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
 }
 
-func (p *FileProcessor) Keep(filename string) error {
-	return p.fs.KeepFile(filename)
+func (p *FileProcessor) Keep(ctx context.Context, filename string) error {
+	return p.transfer(ctx, filename, p.fs.KeepFile)
 }
 
+// KeepAs keeps filename under newName. Other files of its group keep
+// their extensions and take the stem of newName.
+func (p *FileProcessor) KeepAs(ctx context.Context, filename, newName string) error {
+	stem := strings.TrimSuffix(newName, filepath.Ext(newName))
+
+	return p.transfer(ctx, filename, func(ctx context.Context, file string) error {
+		if file == filename {
+			return p.fs.KeepFileAs(ctx, file, newName)
+		}
+		return p.fs.KeepFileAs(ctx, file, stem+filepath.Ext(file))
+	})
+}
+
+func (p *FileProcessor) Copy(ctx context.Context, filename string) error {
+	return p.transfer(ctx, filename, p.fs.CopyFile)
+}
+
+func (p *FileProcessor) Symlink(filename string) error {
+	return p.each(filename, p.fs.SymlinkFile)
+}
+
+func (p *FileProcessor) Hardlink(filename string) error {
+	return p.each(filename, p.fs.HardlinkFile)
+}
+
+// Delete deletes all files of the group. Deleted files cannot be
+// restored, so every member is checked to exist first.
 func (p *FileProcessor) Delete(filename string) error {
-	return p.fs.DeleteFile(filename)
+	files := p.members(filename)
+	if len(files) > 1 {
+		for _, file := range files {
+			if _, err := p.fs.FileSize(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	var errs []error
+	for _, file := range files {
+		if err := p.fs.DeleteFile(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return join(errs)
+}
+
+// Size returns the total size of the group.
+func (p *FileProcessor) Size(filename string) (int64, error) {
+	var total int64
+	for _, file := range p.members(filename) {
+		size, err := p.fs.FileSize(file)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+func (p *FileProcessor) Metadata(filename string) (domain.FileMeta, error) {
+	return p.fs.Metadata(filename)
+}
+
+// Space returns the room left in the target directory.
+func (p *FileProcessor) Space() (domain.TargetSpace, error) {
+	return p.fs.TargetSpace()
+}
+
+// each applies op to every file of the group. When a file fails, the
+// files done before it are restored so the group is never split.
+func (p *FileProcessor) each(filename string, op func(string) error) error {
+	files := p.members(filename)
+	for i, file := range files {
+		err := op(file)
+		if err == nil {
+			continue
+		}
+
+		errs := []error{err}
+		for _, done := range files[:i] {
+			if err := p.fs.Restore(done); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return join(errs)
+	}
+	return nil
+}
+
+// transfer is each for operations that may copy data. Progress of the
+// members adds up, and no member starts once ctx is cancelled.
+func (p *FileProcessor) transfer(ctx context.Context, filename string, op func(context.Context, string) error) error {
+	report := domain.ProgressFrom(ctx)
+	var done, last int64
+	ctx = domain.WithProgress(ctx, func(copied int64) {
+		last = copied
+		report(done + copied)
+	})
+
+	return p.each(filename, func(file string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		done += last
+		last = 0
+		return op(ctx, file)
+	})
+}
+
+// join combines errors, returning a single error unchanged.
+func join(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
 }
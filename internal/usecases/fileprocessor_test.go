@@ -1,10 +1,13 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/rycln/filer/internal/domain"
 	"github.com/rycln/filer/internal/usecases/mocks"
 )
 
@@ -31,9 +34,9 @@ func TestFileProcessor_Keep(t *testing.T) {
 		processor := NewFileProcessor(mockFS)
 		filename := "test.txt"
 
-		mockFS.EXPECT().KeepFile(filename).Return(nil)
+		mockFS.EXPECT().KeepFile(gomock.Any(), filename).Return(nil)
 
-		err := processor.Keep(filename)
+		err := processor.Keep(context.Background(), filename)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -49,9 +52,9 @@ func TestFileProcessor_Keep(t *testing.T) {
 		filename := "test.txt"
 		expectedErr := errors.New("keep failed")
 
-		mockFS.EXPECT().KeepFile(filename).Return(expectedErr)
+		mockFS.EXPECT().KeepFile(gomock.Any(), filename).Return(expectedErr)
 
-		err := processor.Keep(filename)
+		err := processor.Keep(context.Background(), filename)
 
 		if err == nil {
 			t.Error("Expected error, got nil")
@@ -69,9 +72,9 @@ func TestFileProcessor_Keep(t *testing.T) {
 		processor := NewFileProcessor(mockFS)
 		filename := ""
 
-		mockFS.EXPECT().KeepFile(filename).Return(nil)
+		mockFS.EXPECT().KeepFile(gomock.Any(), filename).Return(nil)
 
-		err := processor.Keep(filename)
+		err := processor.Keep(context.Background(), filename)
 
 		if err != nil {
 			t.Errorf("Expected no error with empty filename, got %v", err)
@@ -79,6 +82,194 @@ func TestFileProcessor_Keep(t *testing.T) {
 	})
 }
 
+func TestFileProcessor_KeepVariants(t *testing.T) {
+	t.Run("should delegate rename, copy and link actions to filesystem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+
+		mockFS.EXPECT().KeepFileAs(gomock.Any(), "a.txt", "d.txt").Return(nil)
+		mockFS.EXPECT().CopyFile(gomock.Any(), "a.txt").Return(nil)
+		mockFS.EXPECT().SymlinkFile("b.txt").Return(nil)
+		mockFS.EXPECT().HardlinkFile("c.txt").Return(nil)
+
+		if err := processor.KeepAs(context.Background(), "a.txt", "d.txt"); err != nil {
+			t.Errorf("Expected no rename error, got %v", err)
+		}
+		if err := processor.Copy(context.Background(), "a.txt"); err != nil {
+			t.Errorf("Expected no copy error, got %v", err)
+		}
+		if err := processor.Symlink("b.txt"); err != nil {
+			t.Errorf("Expected no symlink error, got %v", err)
+		}
+		if err := processor.Hardlink("c.txt"); err != nil {
+			t.Errorf("Expected no hardlink error, got %v", err)
+		}
+	})
+
+	t.Run("should return filesystem errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+		expectedErr := errors.New("link failed")
+
+		mockFS.EXPECT().CopyFile(gomock.Any(), "a.txt").Return(expectedErr)
+		mockFS.EXPECT().SymlinkFile("a.txt").Return(expectedErr)
+		mockFS.EXPECT().HardlinkFile("a.txt").Return(expectedErr)
+
+		for _, err := range []error{processor.Copy(context.Background(), "a.txt"), processor.Symlink("a.txt"), processor.Hardlink("a.txt")} {
+			if err != expectedErr {
+				t.Errorf("Expected error %v, got %v", expectedErr, err)
+			}
+		}
+	})
+}
+
+func TestFileProcessor_Groups(t *testing.T) {
+	members := func(filename string) []string {
+		if filename == "DSC001.JPG" {
+			return []string{"DSC001.JPG", "DSC001.ARW", "DSC001.xmp"}
+		}
+		return []string{filename}
+	}
+
+	t.Run("should act on every file of the group", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+
+		gomock.InOrder(
+			mockFS.EXPECT().KeepFile(gomock.Any(), "DSC001.JPG").Return(nil),
+			mockFS.EXPECT().KeepFile(gomock.Any(), "DSC001.ARW").Return(nil),
+			mockFS.EXPECT().KeepFile(gomock.Any(), "DSC001.xmp").Return(nil),
+		)
+
+		if err := processor.Keep(context.Background(), "DSC001.JPG"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should rename members after the group", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+
+		mockFS.EXPECT().KeepFileAs(gomock.Any(), "DSC001.JPG", "beach.jpg").Return(nil)
+		mockFS.EXPECT().KeepFileAs(gomock.Any(), "DSC001.ARW", "beach.ARW").Return(nil)
+		mockFS.EXPECT().KeepFileAs(gomock.Any(), "DSC001.xmp", "beach.xmp").Return(nil)
+
+		if err := processor.KeepAs(context.Background(), "DSC001.JPG", "beach.jpg"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should restore kept files when a member fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+		expectedErr := errors.New("copy failed")
+
+		mockFS.EXPECT().CopyFile(gomock.Any(), "DSC001.JPG").Return(nil)
+		mockFS.EXPECT().CopyFile(gomock.Any(), "DSC001.ARW").Return(expectedErr)
+		mockFS.EXPECT().Restore("DSC001.JPG").Return(nil)
+
+		if err := processor.Copy(context.Background(), "DSC001.JPG"); err != expectedErr {
+			t.Errorf("Expected error %v, got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("should not delete any file when a member is missing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+		expectedErr := errors.New("no such file")
+
+		mockFS.EXPECT().FileSize("DSC001.JPG").Return(int64(10), nil)
+		mockFS.EXPECT().FileSize("DSC001.ARW").Return(int64(0), expectedErr)
+
+		if err := processor.Delete("DSC001.JPG"); err != expectedErr {
+			t.Errorf("Expected error %v, got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("should sum sizes of the group", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+
+		mockFS.EXPECT().FileSize("DSC001.JPG").Return(int64(10), nil)
+		mockFS.EXPECT().FileSize("DSC001.ARW").Return(int64(20), nil)
+		mockFS.EXPECT().FileSize("DSC001.xmp").Return(int64(1), nil)
+
+		if size, err := processor.Size("DSC001.JPG"); err != nil || size != 31 {
+			t.Errorf("Expected size 31, got %d (%v)", size, err)
+		}
+	})
+
+	t.Run("should add up progress of the members", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+
+		copyBytes := func(n int64) func(context.Context, string) error {
+			return func(ctx context.Context, _ string) error {
+				domain.ProgressFrom(ctx)(n)
+				return nil
+			}
+		}
+		mockFS.EXPECT().CopyFile(gomock.Any(), "DSC001.JPG").DoAndReturn(copyBytes(10))
+		mockFS.EXPECT().CopyFile(gomock.Any(), "DSC001.ARW").DoAndReturn(copyBytes(20))
+		mockFS.EXPECT().CopyFile(gomock.Any(), "DSC001.xmp").DoAndReturn(copyBytes(1))
+
+		var reported []int64
+		ctx := domain.WithProgress(context.Background(), func(copied int64) {
+			reported = append(reported, copied)
+		})
+
+		if err := processor.Copy(ctx, "DSC001.JPG"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !slices.Equal(reported, []int64{10, 30, 31}) {
+			t.Errorf("Expected progress [10 30 31], got %v", reported)
+		}
+	})
+
+	t.Run("should not start members once cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS, WithGroups(members))
+		ctx, cancel := context.WithCancel(context.Background())
+
+		mockFS.EXPECT().KeepFile(gomock.Any(), "DSC001.JPG").DoAndReturn(func(context.Context, string) error {
+			cancel()
+			return nil
+		})
+		mockFS.EXPECT().Restore("DSC001.JPG").Return(nil)
+
+		if err := processor.Keep(ctx, "DSC001.JPG"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
 func TestFileProcessor_Delete(t *testing.T) {
 	t.Run("should successfully delete file", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -136,6 +327,86 @@ func TestFileProcessor_Delete(t *testing.T) {
 	})
 }
 
+func TestFileProcessor_Size(t *testing.T) {
+	t.Run("should return file size from filesystem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+		filename := "test.txt"
+
+		mockFS.EXPECT().FileSize(filename).Return(int64(42), nil)
+
+		size, err := processor.Size(filename)
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if size != 42 {
+			t.Errorf("Expected size 42, got %d", size)
+		}
+	})
+
+	t.Run("should return error when filesystem fails to stat file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+		expectedErr := errors.New("stat failed")
+
+		mockFS.EXPECT().FileSize("test.txt").Return(int64(0), expectedErr)
+
+		_, err := processor.Size("test.txt")
+
+		if err != expectedErr {
+			t.Errorf("Expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
+func TestFileProcessor_Metadata(t *testing.T) {
+	t.Run("should return metadata from filesystem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+		meta := domain.FileMeta{Size: 7, Owner: "alice"}
+
+		mockFS.EXPECT().Metadata("test.txt").Return(meta, nil)
+
+		got, err := processor.Metadata("test.txt")
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if got != meta {
+			t.Errorf("Expected %+v, got %+v", meta, got)
+		}
+	})
+}
+
+func TestFileProcessor_Space(t *testing.T) {
+	t.Run("should return target space from filesystem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		processor := NewFileProcessor(mockFS)
+		space := domain.TargetSpace{Free: 100, Quota: -1}
+
+		mockFS.EXPECT().TargetSpace().Return(space, nil)
+
+		got, err := processor.Space()
+
+		if err != nil || got != space {
+			t.Errorf("Expected %+v, got %+v (%v)", space, got, err)
+		}
+	})
+}
+
 func TestFileProcessor_Integration(t *testing.T) {
 	t.Run("should call correct filesystem method for each operation", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -147,11 +418,11 @@ func TestFileProcessor_Integration(t *testing.T) {
 
 		// Expect keep call
 		gomock.InOrder(
-			mockFS.EXPECT().KeepFile(filename).Return(nil),
+			mockFS.EXPECT().KeepFile(gomock.Any(), filename).Return(nil),
 			mockFS.EXPECT().DeleteFile(filename).Return(nil),
 		)
 
-		err := processor.Keep(filename)
+		err := processor.Keep(context.Background(), filename)
 		if err != nil {
 			t.Errorf("Keep failed: %v", err)
 		}
@@ -171,10 +442,10 @@ func TestFileProcessor_Integration(t *testing.T) {
 		filename1 := "file1.txt"
 		filename2 := "file2.txt"
 
-		mockFS.EXPECT().KeepFile(filename1).Return(nil)
+		mockFS.EXPECT().KeepFile(gomock.Any(), filename1).Return(nil)
 		mockFS.EXPECT().DeleteFile(filename2).Return(nil)
 
-		err := processor.Keep(filename1)
+		err := processor.Keep(context.Background(), filename1)
 		if err != nil {
 			t.Errorf("Keep file1 failed: %v", err)
 		}
@@ -5,9 +5,11 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/rycln/filer/internal/domain"
 )
 
 // MockFileSystem is a mock of FileSystem interface.
@@ -33,6 +35,20 @@ func (m *MockFileSystem) EXPECT() *MockFileSystemMockRecorder {
 	return m.recorder
 }
 
+// CopyFile mocks base method.
+func (m *MockFileSystem) CopyFile(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyFile indicates an expected call of CopyFile.
+func (mr *MockFileSystemMockRecorder) CopyFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockFileSystem)(nil).CopyFile), arg0, arg1)
+}
+
 // DeleteFile mocks base method.
 func (m *MockFileSystem) DeleteFile(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -47,16 +63,117 @@ func (mr *MockFileSystemMockRecorder) DeleteFile(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockFileSystem)(nil).DeleteFile), arg0)
 }
 
+// FileSize mocks base method.
+func (m *MockFileSystem) FileSize(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileSize", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileSize indicates an expected call of FileSize.
+func (mr *MockFileSystemMockRecorder) FileSize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileSize", reflect.TypeOf((*MockFileSystem)(nil).FileSize), arg0)
+}
+
+// HardlinkFile mocks base method.
+func (m *MockFileSystem) HardlinkFile(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HardlinkFile", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HardlinkFile indicates an expected call of HardlinkFile.
+func (mr *MockFileSystemMockRecorder) HardlinkFile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardlinkFile", reflect.TypeOf((*MockFileSystem)(nil).HardlinkFile), arg0)
+}
+
 // KeepFile mocks base method.
-func (m *MockFileSystem) KeepFile(arg0 string) error {
+func (m *MockFileSystem) KeepFile(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "KeepFile", arg0)
+	ret := m.ctrl.Call(m, "KeepFile", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // KeepFile indicates an expected call of KeepFile.
-func (mr *MockFileSystemMockRecorder) KeepFile(arg0 interface{}) *gomock.Call {
+func (mr *MockFileSystemMockRecorder) KeepFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepFile", reflect.TypeOf((*MockFileSystem)(nil).KeepFile), arg0, arg1)
+}
+
+// KeepFileAs mocks base method.
+func (m *MockFileSystem) KeepFileAs(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeepFileAs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// KeepFileAs indicates an expected call of KeepFileAs.
+func (mr *MockFileSystemMockRecorder) KeepFileAs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepFileAs", reflect.TypeOf((*MockFileSystem)(nil).KeepFileAs), arg0, arg1, arg2)
+}
+
+// Metadata mocks base method.
+func (m *MockFileSystem) Metadata(arg0 string) (domain.FileMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metadata", arg0)
+	ret0, _ := ret[0].(domain.FileMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Metadata indicates an expected call of Metadata.
+func (mr *MockFileSystemMockRecorder) Metadata(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metadata", reflect.TypeOf((*MockFileSystem)(nil).Metadata), arg0)
+}
+
+// Restore mocks base method.
+func (m *MockFileSystem) Restore(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockFileSystemMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockFileSystem)(nil).Restore), arg0)
+}
+
+// SymlinkFile mocks base method.
+func (m *MockFileSystem) SymlinkFile(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SymlinkFile", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SymlinkFile indicates an expected call of SymlinkFile.
+func (mr *MockFileSystemMockRecorder) SymlinkFile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SymlinkFile", reflect.TypeOf((*MockFileSystem)(nil).SymlinkFile), arg0)
+}
+
+// TargetSpace mocks base method.
+func (m *MockFileSystem) TargetSpace() (domain.TargetSpace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TargetSpace")
+	ret0, _ := ret[0].(domain.TargetSpace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TargetSpace indicates an expected call of TargetSpace.
+func (mr *MockFileSystemMockRecorder) TargetSpace() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepFile", reflect.TypeOf((*MockFileSystem)(nil).KeepFile), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TargetSpace", reflect.TypeOf((*MockFileSystem)(nil).TargetSpace))
 }